@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	initpkg "github.com/dscv103/fionacode/cli/internal/init"
+	"github.com/spf13/cobra"
+)
+
+var (
+	cleanYes    bool
+	cleanForce  bool
+	cleanBackup bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean [directory]",
+	Short: "Remove the files fifi init created",
+	Long: `Remove opencode.json and the .opencode directory from a project.
+
+clean only removes files that are identical to the ones init creates. If any
+file was modified or added, nothing is removed and the files are listed; use
+--force to remove them anyway, and --backup to keep a copy in a timestamped
+.opencode.bak-YYYYMMDD-HHMMSS/ directory first.
+
+clean asks for confirmation unless --yes is given.
+
+If no directory is specified, the current directory is cleaned.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDirectory,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetDir := "."
+		if len(args) > 0 {
+			targetDir = args[0]
+		}
+
+		if !cleanYes {
+			if !isTerminal(os.Stdin) {
+				return fmt.Errorf("refusing to remove files without confirmation; pass --yes")
+			}
+			abs, err := filepath.Abs(targetDir)
+			if err != nil {
+				abs = targetDir
+			}
+			if !confirm(fmt.Sprintf("Remove opencode.json and .opencode from %s?", abs)) {
+				fmt.Println("Aborted.")
+				return nil
+			}
+		}
+
+		result, err := initpkg.Clean(targetDir, initpkg.CleanOptions{
+			Force:  cleanForce,
+			Backup: cleanBackup,
+		})
+		if err != nil {
+			return fmt.Errorf("clean failed: %w\nUse --force to remove them anyway (with --backup to keep a copy)", err)
+		}
+
+		if len(result.Removed) == 0 {
+			fmt.Println("Nothing to remove.")
+			return nil
+		}
+
+		fmt.Printf("%s Removed %d files\n", okMark(), len(result.Removed))
+		if result.BackupDir != "" {
+			fmt.Printf("  Backup: %s\n", result.BackupDir)
+		}
+		return nil
+	},
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func init() {
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Don't ask for confirmation")
+	cleanCmd.Flags().BoolVar(&cleanForce, "force", false, "Also remove modified and additional files")
+	cleanCmd.Flags().BoolVar(&cleanBackup, "backup", false, "Back up the files before removing them")
+	rootCmd.AddCommand(cleanCmd)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/assets"
+	initpkg "github.com/dscv103/fionacode/cli/internal/init"
+	"github.com/dscv103/fionacode/cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion <bash|zsh|fish|powershell>",
+	Short: "Generate a shell completion script",
+	Long: `Generate a completion script for fifi and write it to stdout.
+
+Bash:
+  source <(fifi completion bash)
+  # or permanently:
+  fifi completion bash > /etc/bash_completion.d/fifi
+
+Zsh:
+  fifi completion zsh > "${fpath[1]}/_fifi"
+
+Fish:
+  fifi completion fish > ~/.config/fish/completions/fifi.fish
+
+PowerShell:
+  fifi completion powershell | Out-String | Invoke-Expression`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		switch args[0] {
+		case "bash":
+			err = rootCmd.GenBashCompletionV2(os.Stdout, true)
+		case "zsh":
+			err = rootCmd.GenZshCompletion(os.Stdout)
+		case "fish":
+			err = rootCmd.GenFishCompletion(os.Stdout, true)
+		case "powershell":
+			err = rootCmd.GenPowerShellCompletionWithDesc(os.Stdout)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to generate %s completion: %w", args[0], err)
+		}
+		return nil
+	},
+}
+
+// completeDirectory completes a single directory argument
+func completeDirectory(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+// completePresets completes init --preset values
+func completePresets(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, preset := range assets.Presets() {
+		names = append(names, preset.Name+"\t"+preset.Description)
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeCategories completes validate --ignore values
+func completeCategories(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	var names []string
+	for _, c := range validate.Categories() {
+		names = append(names, string(c))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeComponents completes init --only values, which are comma-separated
+func completeComponents(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+
+	var names []string
+	for _, c := range initpkg.Components() {
+		names = append(names, prefix+string(c))
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// completeEmbeddedAgents completes init --agents values, which are
+// comma-separated, with the agents of the embedded configuration
+func completeEmbeddedAgents(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+
+	content, err := assets.GetOpencodeJSON()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var config validate.OpencodeConfig
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(config.Agent))
+	for name := range config.Agent {
+		names = append(names, prefix+name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+func init() {
+	// Replace cobra's default completion command with the one above
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(completionCmd)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// projectConfigFile is the project-local config file, looked up in the
+// current directory
+const projectConfigFile = ".fifirc"
+
+// settings holds the defaults read from the config files
+var settings = viper.New()
+
+// configurableFlags are the flags whose defaults config files may set. Flags
+// that destroy data, like --force, or that only make sense for a single run
+// are left out.
+var configurableFlags = map[string]bool{
+	"no-color":         true,
+	"log-level":        true,
+	"log-format":       true,
+	"from":             true,
+	"preset":           true,
+	"agents":           true,
+	"only":             true,
+	"gitignore":        true,
+	"env-example":      true,
+	"minimal-json":     true,
+	"manifest":         true,
+	"no-post-validate": true,
+	"no-backup":        true,
+	"hook":             true,
+	"ignore":           true,
+	"strict":           true,
+	"use-schema":       true,
+	"timeout":          true,
+	"retries":          true,
+	"prerelease":       true,
+}
+
+// userOnlySettings may only be set in the user config file: a .fifirc comes
+// with whatever project it is in, and these run shell commands (hook),
+// choose the code init scaffolds (from), or control what is sent to GitHub
+// and when (github-token, update-check)
+var userOnlySettings = []string{"hook", "from", "github-token", "update-check"}
+
+// userConfigFile returns the path of the user config file:
+// $XDG_CONFIG_HOME/fifi/config.yaml, or ~/.config/fifi/config.yaml
+func userConfigFile() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "fifi", "config.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "fifi", "config.yaml"), nil
+}
+
+// loadSettings reads the user config file and then .fifirc, whose values
+// take precedence. Missing files are skipped, and user-only settings in
+// .fifirc are ignored with a warning.
+func loadSettings() error {
+	settings.SetConfigType("yaml")
+
+	var files []string
+	if path, err := userConfigFile(); err == nil {
+		files = append(files, path)
+	}
+	// A -C directory that doesn't exist yet has no .fifirc, and the one in
+	// the current directory belongs to another project
+	if missingTarget == "" {
+		files = append(files, projectConfigFile)
+	}
+
+	for _, path := range files {
+		config := viper.New()
+		config.SetConfigType("yaml")
+		f, err := os.Open(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		err = config.ReadConfig(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+
+		values := config.AllSettings()
+		if path == projectConfigFile {
+			for _, key := range userOnlySettings {
+				if _, ok := values[key]; ok {
+					fmt.Fprintf(os.Stderr, "%s ignoring %s in %s: it can only be set in the user config file\n", warningMarkFor(os.Stderr), key, path)
+					delete(values, key)
+				}
+			}
+		}
+		if err := settings.MergeConfigMap(values); err != nil {
+			return fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// applySettings sets every configurable flag of cmd that wasn't given on the
+// command line to the config file value of the same name, so flags always
+// win
+func applySettings(cmd *cobra.Command) error {
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || !configurableFlags[flag.Name] || !settings.IsSet(flag.Name) {
+			return
+		}
+
+		// A YAML list sets each element as is; a single string is parsed
+		// like the flag's value on the command line, so hook: "echo a b"
+		// stays one hook and only: config,prompts still splits on commas
+		var setErr error
+		slice, isSlice := flag.Value.(pflag.SliceValue)
+		if list, ok := settings.Get(flag.Name).([]any); ok && isSlice {
+			values := make([]string, len(list))
+			for i, value := range list {
+				values[i] = fmt.Sprint(value)
+			}
+			setErr = slice.Replace(values)
+		} else {
+			setErr = flag.Value.Set(settings.GetString(flag.Name))
+		}
+		if setErr != nil {
+			err = fmt.Errorf("invalid %s value in config file: %w", flag.Name, setErr)
+		}
+	})
+	return err
+}
+
+// loadConfig applies the config files to the flags of the command being run
+func loadConfig(cmd *cobra.Command) error {
+	if err := loadSettings(); err != nil {
+		return err
+	}
+	return applySettings(cmd)
+}
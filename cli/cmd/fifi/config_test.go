@@ -0,0 +1,66 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestLoadSettingsUserOnly(t *testing.T) {
+	userDir := t.TempDir()
+	projectDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", userDir)
+
+	if err := os.MkdirAll(filepath.Join(userDir, "fifi"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	user := "github-token: user-token\nfrom: /home/me/template\n"
+	if err := os.WriteFile(filepath.Join(userDir, "fifi", "config.yaml"), []byte(user), 0644); err != nil {
+		t.Fatal(err)
+	}
+	project := `hook: ["touch pwned.txt"]
+from: github:attacker/template
+github-token: project-token
+update-check: true
+strict: true
+`
+	if err := os.WriteFile(filepath.Join(projectDir, projectConfigFile), []byte(project), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(projectDir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	saved := settings
+	settings = viper.New()
+	t.Cleanup(func() { settings = saved })
+
+	if err := loadSettings(); err != nil {
+		t.Fatalf("loadSettings() error = %v", err)
+	}
+
+	tests := []struct {
+		key  string
+		want any
+	}{
+		{"hook", nil},
+		{"from", "/home/me/template"},
+		{"github-token", "user-token"},
+		{"update-check", nil},
+		// Settings a project may choose still apply
+		{"strict", true},
+	}
+	for _, tt := range tests {
+		if got := settings.Get(tt.key); got != tt.want {
+			t.Errorf("%s = %v, want %v", tt.key, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+)
+
+// mutateConfig rewrites the opencode.json in targetDir with edit, which gets
+// the file's raw contents and returns the new ones. Edits should go through
+// jsonedit so only the entry that changes differs and the rest of the file
+// keeps its formatting and key order. The file is replaced atomically and
+// keeps its permissions; nothing is written if edit changes nothing.
+func mutateConfig(targetDir string, edit func(content []byte) ([]byte, error)) error {
+	configPath := validate.ConfigPath(targetDir)
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read opencode.json: %w", err)
+	}
+	updated, err := edit(content)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(updated, content) {
+		return nil
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(configPath); err == nil {
+		mode = info.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(configPath), ".opencode-*.json")
+	if err != nil {
+		return fmt.Errorf("failed to write opencode.json: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(updated); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write opencode.json: %w", err)
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write opencode.json: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write opencode.json: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), configPath); err != nil {
+		return fmt.Errorf("failed to write opencode.json: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/assets"
+	initpkg "github.com/dscv103/fionacode/cli/internal/init"
+	"github.com/spf13/cobra"
+)
+
+var compareRemote string
+
+// embeddedAssetsDir is where the framework a release embeds lives in the
+// repository
+const embeddedAssetsDir = "cli/internal/assets/embedded"
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [directory]",
+	Short: "Compare a project against the embedded configuration",
+	Long: `Compare opencode.json, .opencode/prompts and .opencode/tool in a project
+against the versions embedded in fifi.
+
+Each file is reported as:
+  identical  the project file matches the embedded file
+  modified   the project file differs from the embedded file
+  added      fifi ships the file but the project doesn't have it
+  removed    the project has a file fifi no longer ships
+
+With --compare-remote, the project is compared against the framework
+configuration of a fifi release instead, e.g. "fifi diff --compare-remote
+v2.0.0" or "--compare-remote latest". The release's files are downloaded
+from GitHub into the user cache directory (like init --from github:...) and
+nothing needs to be installed. The agents and tools in opencode.json that
+were added, removed or changed are listed as well.
+
+If no directory is specified, the current directory is compared.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDirectory,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetDir := "."
+		if len(args) > 0 {
+			targetDir = args[0]
+		}
+
+		if _, err := os.Stat(targetDir); err != nil {
+			return fmt.Errorf("failed to read %s: %w", targetDir, err)
+		}
+
+		var src initpkg.Source
+		if compareRemote != "" {
+			tag, err := remoteFrameworkTag(cmd.Context(), compareRemote)
+			if err != nil {
+				return fmt.Errorf("invalid --compare-remote value: %w", err)
+			}
+			dir, err := resolveTemplateDir(cmd.Context(), githubTemplatePrefix+githubRepo+"/"+embeddedAssetsDir+"@"+tag, false)
+			if err != nil {
+				return fmt.Errorf("failed to get framework %s: %w", tag, err)
+			}
+			if src, err = initpkg.NewDirSource(dir); err != nil {
+				return fmt.Errorf("failed to read framework %s: %w", tag, err)
+			}
+			fmt.Printf("Comparing against framework %s\n\n", tag)
+		} else if framework, err := assets.Manifest(); err == nil {
+			fmt.Printf("Comparing against embedded framework %s\n\n", framework.FrameworkVersion)
+		}
+
+		diffs, err := initpkg.Diff(targetDir, src)
+		if err != nil {
+			return fmt.Errorf("failed to compare %s: %w", targetDir, err)
+		}
+
+		counts := make(map[initpkg.FileStatus]int)
+		for _, diff := range diffs {
+			fmt.Printf("  %-10s %s\n", diff.Status, diff.Path)
+			counts[diff.Status]++
+		}
+
+		fmt.Printf("\n%d identical, %d modified, %d added, %d removed\n",
+			counts[initpkg.StatusIdentical], counts[initpkg.StatusModified],
+			counts[initpkg.StatusAdded], counts[initpkg.StatusRemoved])
+
+		if compareRemote != "" {
+			configDiff, err := initpkg.DiffConfig(targetDir, src)
+			if err != nil {
+				return fmt.Errorf("failed to compare %s: %w", targetDir, err)
+			}
+			printEntryDiffs("Agents", configDiff.Agents)
+			printEntryDiffs("Tools", configDiff.Tools)
+		}
+		return nil
+	},
+}
+
+// printEntryDiffs prints the changed opencode.json entries of one section
+func printEntryDiffs(section string, diffs []initpkg.EntryDiff) {
+	fmt.Printf("\n%s:\n", section)
+	if len(diffs) == 0 {
+		fmt.Println("  no changes")
+		return
+	}
+	for _, diff := range diffs {
+		fmt.Printf("  %-10s %s\n", diff.Status, diff.Name)
+	}
+}
+
+// remoteFrameworkTag returns the release tag --compare-remote names:
+// "latest" is the latest release, and a version without the "v" prefix
+// gets one
+func remoteFrameworkTag(ctx context.Context, version string) (string, error) {
+	if version == "latest" {
+		release, err := getLatestRelease(ctx)
+		if err != nil {
+			return "", err
+		}
+		return release.TagName, nil
+	}
+	if !strings.HasPrefix(version, "v") && canonicalVersion(version) != "" {
+		version = "v" + version
+	}
+	return version, nil
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&compareRemote, "compare-remote", "", "Compare against the framework of this fifi release (e.g. v2.0.0 or latest) instead of the embedded one")
+	diffCmd.RegisterFlagCompletionFunc("compare-remote", cobra.NoFileCompletions)
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+// doctorCheck is one line of the doctor checklist
+type doctorCheck struct {
+	name string
+	ok   bool
+	// critical checks make doctor exit non-zero when they fail
+	critical bool
+	// details are printed below a failed check
+	details []string
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor [directory]",
+	Short: "Diagnose the FionaCode setup of a project",
+	Long: `Check everything a FionaCode project needs to run:
+
+  - opencode.json exists and passes validation
+  - the prompt files and tools agents reference exist
+  - the opencode binary is on PATH
+  - the commands of local MCP servers can be found
+
+Failed configuration checks are critical and make doctor exit with status 1;
+missing programs are reported as warnings.
+
+If no directory is specified, the current directory is checked.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDirectory,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetDir := "."
+		if len(args) > 0 {
+			targetDir = args[0]
+		}
+
+		checks := doctorChecks(targetDir)
+
+		failed := 0
+		for _, check := range checks {
+			marker := okMark()
+			if !check.ok {
+				marker = warningMark()
+				if check.critical {
+					marker = errorMark()
+					failed++
+				}
+			}
+			fmt.Printf("  %s %s\n", marker, check.name)
+			if !check.ok {
+				for _, detail := range check.details {
+					fmt.Printf("      %s\n", detail)
+				}
+			}
+		}
+
+		if failed > 0 {
+			return withExitCode(cmd, 1, fmt.Errorf("\n%d critical check(s) failed", failed))
+		}
+		fmt.Printf("\n%s No critical problems found\n", okMark())
+		return nil
+	},
+}
+
+// doctorChecks runs every check against the project in targetDir
+func doctorChecks(targetDir string) []doctorCheck {
+	var checks []doctorCheck
+
+	_, err := os.Stat(validate.ConfigPath(targetDir))
+	checks = append(checks, doctorCheck{
+		name:     "opencode.json present",
+		ok:       err == nil,
+		critical: true,
+		details:  []string{"run 'fifi init' to create it"},
+	})
+	if err != nil {
+		return append(checks, opencodeCheck())
+	}
+
+	issues, err := validate.Validate(targetDir, validate.Options{})
+	if err != nil {
+		checks = append(checks, doctorCheck{
+			name:     "opencode.json valid",
+			critical: true,
+			details:  []string{err.Error()},
+		})
+		return append(checks, opencodeCheck())
+	}
+
+	// Split the validation issues into the checklist items they concern
+	fileCategories := map[validate.Category]string{
+		validate.CategoryMissingDirectory: "directories",
+		validate.CategoryEmptyDirectory:   "directories",
+		validate.CategoryMissingPrompt:    "prompts",
+		validate.CategoryPromptPath:       "prompts",
+		validate.CategoryUnknownTool:      "tools",
+	}
+	var configDetails []string
+	fileDetails := make(map[string][]string)
+	for _, issue := range issues {
+		if issue.Severity != validate.SeverityError {
+			continue
+		}
+		if item, ok := fileCategories[issue.Category]; ok {
+			fileDetails[item] = append(fileDetails[item], issue.Message)
+		} else {
+			configDetails = append(configDetails, issue.Message)
+		}
+	}
+
+	checks = append(checks,
+		doctorCheck{name: "opencode.json valid", ok: len(configDetails) == 0, critical: true, details: configDetails},
+		doctorCheck{name: ".opencode directories exist", ok: len(fileDetails["directories"]) == 0, critical: true, details: fileDetails["directories"]},
+		doctorCheck{name: "referenced prompt files exist", ok: len(fileDetails["prompts"]) == 0, critical: true, details: fileDetails["prompts"]},
+		doctorCheck{name: "referenced tools exist", ok: len(fileDetails["tools"]) == 0, critical: true, details: fileDetails["tools"]},
+		opencodeCheck(),
+	)
+
+	if config, err := validate.LoadConfig(targetDir); err == nil {
+		checks = append(checks, mcpCommandChecks(config)...)
+	}
+
+	return checks
+}
+
+// opencodeInstallCommand installs the opencode binary
+const opencodeInstallCommand = "curl -fsSL https://opencode.ai/install | bash"
+
+// opencodeCheck checks that the opencode binary is on PATH
+func opencodeCheck() doctorCheck {
+	check := doctorCheck{name: "opencode on PATH"}
+	if path, err := exec.LookPath("opencode"); err == nil {
+		check.ok = true
+		check.name += " (" + path + ")"
+	} else {
+		check.details = []string{"install OpenCode from https://opencode.ai: " + opencodeInstallCommand}
+	}
+	return check
+}
+
+// mcpCommandChecks checks that the command of every enabled local MCP
+// server can be found
+func mcpCommandChecks(config validate.OpencodeConfig) []doctorCheck {
+	commands := make(map[string]string)
+	for name, server := range config.MCP {
+		if server.IsEnabled() && len(server.Command) > 0 {
+			commands[name] = server.Command[0]
+		}
+	}
+	for name, server := range config.MCPServers {
+		if server.Command != "" {
+			commands[name] = server.Command
+		}
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var checks []doctorCheck
+	for _, name := range names {
+		command := commands[name]
+		_, err := exec.LookPath(command)
+		checks = append(checks, doctorCheck{
+			name:    fmt.Sprintf("MCP server %s command (%s) on PATH", name, command),
+			ok:      err == nil,
+			details: []string{fmt.Sprintf("%s is not on PATH; the server won't start", command)},
+		})
+	}
+	return checks
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
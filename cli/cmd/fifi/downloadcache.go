@@ -0,0 +1,46 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// downloadCacheDir returns <user cache dir>/fifi/downloads
+func downloadCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fifi", "downloads"), nil
+}
+
+// partialDownloadPath returns the .part file an interrupted download of
+// the asset of release tag is resumed from
+func partialDownloadPath(tag, assetName string) (string, error) {
+	dir, err := downloadCacheDir()
+	if err != nil {
+		return "", err
+	}
+	// Tags and asset names come from the GitHub API; keep them to one path element
+	name := strings.NewReplacer("/", "_", "\\", "_").Replace(tag + "_" + assetName)
+	return filepath.Join(dir, name+".part"), nil
+}
+
+// openPartialDownload opens, creating if needed, the .part file of an
+// asset download so an interrupted update can continue where it stopped.
+// If the cache dir is unavailable a fresh temp file is used instead, which
+// can't be resumed.
+func openPartialDownload(tag, assetName, tmpPattern string) (*os.File, error) {
+	path, err := partialDownloadPath(tag, assetName)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0755)
+	}
+	if err == nil {
+		var file *os.File
+		if file, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644); err == nil {
+			return file, nil
+		}
+	}
+	return os.CreateTemp("", tmpPattern)
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/assets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	extractOutput string
+)
+
+var extractCmd = &cobra.Command{
+	Use:   "extract <path>",
+	Short: "Write a single embedded file to stdout",
+	Long: `Write a single embedded prompt, tool or config file to stdout, or to a file with -o.
+
+The path is relative to .opencode, e.g. "tool/task_tracker.ts" or
+"prompts/docs.txt". "opencode.json" extracts the configuration and the
+".opencode/" prefix is optional. Run "fifi list" to see every embedded file.
+
+Example:
+  fifi extract prompts/docs.txt > docs.txt`,
+	Args: cobra.ExactArgs(1),
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		files, err := embeddedFiles()
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		names := make([]string, 0, len(files))
+		for _, file := range files {
+			names = append(names, extractName(file))
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, err := resolveEmbeddedFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		content, err := assets.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", args[0], err)
+		}
+
+		if extractOutput != "" {
+			if err := os.WriteFile(extractOutput, content, assets.FileMode(file)); err != nil {
+				return fmt.Errorf("failed to write %s: %w", extractOutput, err)
+			}
+			return nil
+		}
+
+		_, err = os.Stdout.Write(content)
+		return err
+	},
+}
+
+// resolveEmbeddedFile maps a user-supplied name to an embedded asset path
+func resolveEmbeddedFile(name string) (string, error) {
+	files, err := embeddedFiles()
+	if err != nil {
+		return "", err
+	}
+
+	want := strings.TrimPrefix(strings.TrimPrefix(name, "./"), ".opencode/")
+	var names []string
+	for _, file := range files {
+		short := extractName(file)
+		if short == want {
+			return file, nil
+		}
+		names = append(names, short)
+	}
+
+	return "", fmt.Errorf("no embedded file named %q; valid names:\n  %s", name, strings.Join(names, "\n  "))
+}
+
+// extractName returns the name extract accepts for an embedded asset path
+func extractName(file string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(file, "embedded/"), ".opencode/")
+}
+
+func init() {
+	extractCmd.Flags().StringVarP(&extractOutput, "output", "o", "", "Write to this file instead of stdout")
+	rootCmd.AddCommand(extractCmd)
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// runHooks runs each init --hook command through the shell in targetDir,
+// in order, stopping at the first one that fails. FIFI_TARGET_DIR holds the
+// absolute project directory and FIFI_PRESET the scaffolded preset. The
+// hooks' standard output goes to out.
+func runHooks(targetDir string, hooks []string, preset string, out io.Writer) error {
+	dir, err := filepath.Abs(targetDir)
+	if err != nil {
+		return err
+	}
+	env := append(os.Environ(), "FIFI_TARGET_DIR="+dir, "FIFI_PRESET="+preset)
+
+	for _, hook := range hooks {
+		fmt.Fprintf(out, "\nRunning hook: %s\n", hook)
+
+		cmd := hookCommand(hook)
+		cmd.Dir = dir
+		cmd.Env = env
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = out
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hook %q failed: %w", hook, err)
+		}
+	}
+	return nil
+}
+
+// hookCommand returns the shell invocation that runs command
+func hookCommand(command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", command)
+	}
+	return exec.Command("sh", "-c", command)
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultGitHubAPI is the GitHub API base used unless FIFI_GITHUB_API is set
+	defaultGitHubAPI = "https://api.github.com"
+
+	// githubRepo is the repository fifi releases are published from
+	githubRepo = "dscv103/fionacode"
+	// githubRepoPath is githubRepo's path in the GitHub API
+	githubRepoPath = "/repos/" + githubRepo
+)
+
+// githubAPIBase returns the GitHub API base URL. Set FIFI_GITHUB_API to
+// target GitHub Enterprise, e.g. https://github.example.com/api/v3.
+func githubAPIBase() string {
+	if base := os.Getenv("FIFI_GITHUB_API"); base != "" {
+		return strings.TrimRight(base, "/")
+	}
+	return defaultGitHubAPI
+}
+
+// githubReleasesAPI returns the releases endpoint of the fifi repository
+func githubReleasesAPI() string {
+	return githubAPIBase() + githubRepoPath + "/releases"
+}
+
+// httpClient is used for every update request. It honors HTTP_PROXY,
+// HTTPS_PROXY and NO_PROXY, and bounds connection setup and the wait for
+// response headers so a hung server can't block forever. Overall request
+// deadlines come from the context each request is made with (see
+// requestTimeout).
+var httpClient = &http.Client{
+	Transport: loggingTransport{base: httpTransport},
+}
+
+// httpTransport is the transport behind httpClient
+var httpTransport = &http.Transport{
+	Proxy: http.ProxyFromEnvironment,
+	DialContext: (&net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}).DialContext,
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: 30 * time.Second,
+	IdleConnTimeout:       90 * time.Second,
+}
+
+var (
+	caBundleOnce sync.Once
+	caBundleErr  error
+)
+
+// updateClient returns httpClient, first adding the certificates in the PEM
+// file named by FIFI_CA_BUNDLE to its trusted roots so releases can be
+// fetched from mirrors behind a corporate CA. Verification stays on; the
+// system roots are still trusted.
+func updateClient() (*http.Client, error) {
+	caBundleOnce.Do(func() {
+		caBundleErr = loadCABundle(httpTransport, os.Getenv("FIFI_CA_BUNDLE"))
+	})
+	return httpClient, caBundleErr
+}
+
+// loadCABundle adds the certificates in the PEM file at path to the root
+// CAs transport trusts; an empty path leaves transport unchanged
+func loadCABundle(transport *http.Transport, path string) error {
+	if path == "" {
+		return nil
+	}
+
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read FIFI_CA_BUNDLE: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return fmt.Errorf("FIFI_CA_BUNDLE %s contains no PEM certificates", path)
+	}
+
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return nil
+}
+
+// githubToken returns the token sent to the GitHub API, preferring
+// FIFI_GITHUB_TOKEN over GITHUB_TOKEN over the github-token config setting
+func githubToken() string {
+	if token := os.Getenv("FIFI_GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		return token
+	}
+	return settings.GetString("github-token")
+}
+
+// githubGet performs a GitHub API request, authenticating when a token is
+// configured to avoid the 60 requests/hour unauthenticated rate limit
+func githubGet(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	if token := githubToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client, err := updateClient()
+	if err != nil {
+		return nil, permanent(err)
+	}
+	return client.Do(req)
+}
+
+// httpGet performs a plain GET request, used for release asset downloads
+func httpGet(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	client, err := updateClient()
+	if err != nil {
+		return nil, permanent(err)
+	}
+	return client.Do(req)
+}
+
+// httpGetFrom performs a GET request for the content of url starting at
+// byte offset, using a range request when offset is positive
+func httpGetFrom(ctx context.Context, url string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	client, err := updateClient()
+	if err != nil {
+		return nil, permanent(err)
+	}
+	return client.Do(req)
+}
+
+// githubStatusError describes an unexpected GitHub API response status
+func githubStatusError(resp *http.Response) error {
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests {
+		if reset := resp.Header.Get("X-RateLimit-Reset"); reset != "" && resp.Header.Get("X-RateLimit-Remaining") == "0" {
+			msg := "GitHub API rate limit exceeded"
+			if unix, err := strconv.ParseInt(reset, 10, 64); err == nil {
+				msg += fmt.Sprintf(" (resets at %s)", time.Unix(unix, 0).Format(time.Kitchen))
+			}
+			if githubToken() == "" {
+				msg += "; set GITHUB_TOKEN or FIFI_GITHUB_TOKEN to raise the limit"
+			}
+			return fmt.Errorf("%s", msg)
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return fmt.Errorf("no releases found for %s", githubRepo)
+	case http.StatusForbidden, http.StatusTooManyRequests:
+		// Without the rate limit headers, e.g. from a proxy or secondary limits
+		msg := fmt.Sprintf("GitHub API refused the request (status %d), most likely because of rate limiting; try again later", resp.StatusCode)
+		if githubToken() == "" {
+			msg += " or set GITHUB_TOKEN or FIFI_GITHUB_TOKEN"
+		}
+		return fmt.Errorf("%s", msg)
+	}
+	return fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+}
+
+// maxRetries is how many times a failed request is retried. It is zero
+// outside the update command so background checks never add delays.
+var maxRetries = 0
+
+// requestTimeout bounds each attempt of a metadata request, including
+// reading the response body, and how long a download may go without
+// receiving data; zero means no limit
+var requestTimeout = 30 * time.Second
+
+// permanentError marks an error that retrying won't fix (e.g. a 404)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// permanent marks err as not retryable
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// statusError turns a non-200 response into an error, retryable only for 5xx
+func statusError(resp *http.Response, err error) error {
+	if resp.StatusCode >= 500 {
+		return err
+	}
+	return permanent(err)
+}
+
+// withRetry calls fn until it succeeds, fails with a permanent error, or
+// maxRetries retries have been made, backing off exponentially in between.
+// Each attempt gets a context bounded by requestTimeout; canceling ctx
+// stops both the attempt in flight and any further retries.
+func withRetry(ctx context.Context, what string, fn func(ctx context.Context) error) error {
+	return retry(ctx, what, attemptWithTimeout, fn)
+}
+
+// withDownloadRetry is withRetry for file downloads, which can take much
+// longer than requestTimeout on a slow connection. An attempt has no overall
+// deadline; it is aborted once nothing has been received for requestTimeout,
+// counting the data fn reads through watchStall.
+func withDownloadRetry(ctx context.Context, what string, fn func(ctx context.Context) error) error {
+	return retry(ctx, what, attemptWithStallTimeout, fn)
+}
+
+// retry implements withRetry and withDownloadRetry, running each attempt
+// of fn through attempt
+func retry(ctx context.Context, what string, attemptFn func(context.Context, func(context.Context) error) error, fn func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := attemptFn(ctx, fn)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("%s canceled: %w", what, ctx.Err())
+		}
+
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return perm.err
+		}
+		if attempt >= maxRetries {
+			return err
+		}
+
+		delay := retryDelay(attempt)
+		fmt.Fprintf(os.Stderr, "Failed to %s: %v\nRetrying in %s (%d/%d)...\n", what, err, delay, attempt+1, maxRetries)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return fmt.Errorf("%s canceled: %w", what, ctx.Err())
+		}
+	}
+}
+
+// attemptWithTimeout runs fn with a context bounded by requestTimeout,
+// reporting a timeout in plain words
+func attemptWithTimeout(ctx context.Context, fn func(ctx context.Context) error) error {
+	if requestTimeout <= 0 {
+		return fn(ctx)
+	}
+
+	attemptCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	err := fn(attemptCtx)
+	if err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("timed out after %s", requestTimeout)
+	}
+	return err
+}
+
+// errStalled is the cause of canceling a download that stopped receiving data
+var errStalled = errors.New("download stalled")
+
+// stallTimerKey is the context key of the stall timer of a download attempt
+type stallTimerKey struct{}
+
+// attemptWithStallTimeout runs fn with a context that is canceled once
+// nothing has been read through watchStall for requestTimeout. The timer
+// starts right away, so a server that never answers is caught too.
+func attemptWithStallTimeout(ctx context.Context, fn func(ctx context.Context) error) error {
+	if requestTimeout <= 0 {
+		return fn(ctx)
+	}
+
+	attemptCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	timer := time.AfterFunc(requestTimeout, func() { cancel(errStalled) })
+	defer timer.Stop()
+
+	err := fn(context.WithValue(attemptCtx, stallTimerKey{}, timer))
+	if err != nil && ctx.Err() == nil && errors.Is(context.Cause(attemptCtx), errStalled) {
+		return fmt.Errorf("no data received for %s", requestTimeout)
+	}
+	return err
+}
+
+// watchStall returns r, resetting the stall timer of the download attempt
+// ctx belongs to whenever data is read from it
+func watchStall(ctx context.Context, r io.Reader) io.Reader {
+	timer, ok := ctx.Value(stallTimerKey{}).(*time.Timer)
+	if !ok {
+		return r
+	}
+	return &stallReader{r: r, timer: timer}
+}
+
+// stallReader resets timer each time data is read
+type stallReader struct {
+	r     io.Reader
+	timer *time.Timer
+}
+
+func (s *stallReader) Read(p []byte) (int, error) {
+	n, err := s.r.Read(p)
+	if n > 0 {
+		s.timer.Reset(requestTimeout)
+	}
+	return n, err
+}
+
+// retryDelay returns the backoff before retry number attempt+1: 1s, 2s, 4s, ... capped at 30s
+func retryDelay(attempt int) time.Duration {
+	delay := time.Second << attempt
+	if delay > 30*time.Second || delay <= 0 {
+		delay = 30 * time.Second
+	}
+	return delay
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAttemptWithStallTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		pause   time.Duration
+		wantErr string
+	}{
+		// Takes longer than the timeout overall, but data keeps arriving
+		{name: "slow download", pause: 50 * time.Millisecond},
+		{name: "stalled download", pause: time.Second, wantErr: "no data received"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				for i := 0; i < 6; i++ {
+					w.Write([]byte("x"))
+					w.(http.Flusher).Flush()
+					select {
+					case <-time.After(tt.pause):
+					case <-r.Context().Done():
+						return
+					}
+				}
+			}))
+			defer server.Close()
+
+			saved := requestTimeout
+			requestTimeout = 200 * time.Millisecond
+			defer func() { requestTimeout = saved }()
+
+			var body string
+			err := attemptWithStallTimeout(context.Background(), func(ctx context.Context) error {
+				req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+				if err != nil {
+					return err
+				}
+				resp, err := http.DefaultClient.Do(req)
+				if err != nil {
+					return err
+				}
+				defer resp.Body.Close()
+				data, err := io.ReadAll(watchStall(ctx, resp.Body))
+				body = string(data)
+				return err
+			})
+
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("attemptWithStallTimeout() error = %v", err)
+				}
+				if body != "xxxxxx" {
+					t.Errorf("body = %q, want %q", body, "xxxxxx")
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("attemptWithStallTimeout() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
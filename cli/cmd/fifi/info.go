@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info [directory]",
+	Short: "Summarize a FionaCode project at a glance",
+	Long: `Print a short overview of a project: whether its configuration is valid,
+its agents, how many tools are enabled, how many MCP servers it defines and
+whether any referenced prompt files are missing.
+
+info is read-only and informational: problems are pointed out but never make
+it exit with a non-zero status. Run 'fifi validate' for the full list.
+
+If no directory is specified, the current directory is described.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDirectory,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetDir := "."
+		if len(args) > 0 {
+			targetDir = args[0]
+		}
+
+		if abs, err := filepath.Abs(targetDir); err == nil {
+			fmt.Printf("Project:     %s\n", abs)
+		}
+
+		issues, err := validate.Validate(targetDir, validate.Options{})
+		var parseErr *validate.ParseError
+		switch {
+		case errors.Is(err, validate.ErrConfigNotFound):
+			fmt.Printf("Config:      %s not found (run 'fifi init')\n", filepath.Base(validate.ConfigPath(targetDir)))
+			return nil
+		case errors.As(err, &parseErr):
+			fmt.Printf("Config:      %s %v\n", errorMark(), err)
+			return nil
+		case err != nil:
+			return err
+		}
+
+		status := okMark() + " valid"
+		if counts := countSeverities(issues); counts.Errors > 0 {
+			status = fmt.Sprintf("%s %s", errorMark(), countIssues(issues))
+		} else if counts.Warnings > 0 {
+			status = fmt.Sprintf("%s valid with %d warning(s)", warningMark(), counts.Warnings)
+		}
+		fmt.Printf("Config:      %s %s\n", filepath.Base(validate.ConfigPath(targetDir)), status)
+
+		summary, err := validate.Summarize(targetDir)
+		if err != nil {
+			return fmt.Errorf("failed to summarize %s: %w", targetDir, err)
+		}
+
+		fmt.Printf("Agents:      %d", summary.Agents)
+		if len(summary.AgentNames) > 0 {
+			fmt.Printf(" (%s)", strings.Join(summary.AgentNames, ", "))
+		}
+		fmt.Println()
+		fmt.Printf("Tools:       %d enabled, %d disabled\n", summary.EnabledTools, summary.DisabledTools)
+		fmt.Printf("MCP servers: %d\n", summary.MCPServers)
+
+		var missing []string
+		for _, agent := range summary.AgentDetails {
+			if agent.Prompt != "" && !agent.HasPrompt {
+				missing = append(missing, fmt.Sprintf("%s (%s)", agent.Prompt, agent.Name))
+			}
+		}
+		if len(missing) == 0 {
+			fmt.Printf("Prompts:     %s all referenced prompt files present\n", okMark())
+		} else {
+			fmt.Printf("Prompts:     %s %d missing:\n", errorMark(), len(missing))
+			for _, prompt := range missing {
+				fmt.Printf("               %s\n", prompt)
+			}
+		}
+
+		if len(issues) > 0 {
+			fmt.Println("\nRun 'fifi validate' for details.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
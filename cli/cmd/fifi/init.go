@@ -1,53 +1,564 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"github.com/dscv103/fionacode/cli/internal/assets"
 	initpkg "github.com/dscv103/fionacode/cli/internal/init"
+	"github.com/dscv103/fionacode/cli/internal/validate"
 	"github.com/spf13/cobra"
 )
 
+var (
+	mergeInit        bool
+	forceInit        bool
+	noBackup         bool
+	onlyComponents   []string
+	templateDir      string
+	skipPostValidate bool
+	writeGitignore   bool
+	writeEnvExample  bool
+	initPreset       string
+	printFiles       bool
+	minimalJSON      bool
+	initOutput       string
+	initHooks        []string
+	writeManifest    bool
+	refreshTemplate  bool
+	initInto         bool
+	initDryRun       bool
+	initJSON         bool
+	initAgents       []string
+)
+
+// initReport is the --json output of fifi init
+type initReport struct {
+	// Directory is the absolute project directory
+	Directory string `json:"directory"`
+	// Config is the absolute path of opencode.json
+	Config string `json:"config"`
+	Merged bool   `json:"merged"`
+	// Prompts and Tools list the absolute paths of the prompt and tool files written
+	Prompts []string `json:"prompts"`
+	Tools   []string `json:"tools"`
+	// Files lists every file written, including opencode.json
+	Files     []string `json:"files"`
+	BackupDir string   `json:"backupDir,omitempty"`
+	Conflicts []string `json:"conflicts,omitempty"`
+}
+
+// initErrorReport is what fifi init --json prints to stderr when it fails
+type initErrorReport struct {
+	Error string `json:"error"`
+	// Issues are the problems post-init validation found, if that failed
+	Issues []validate.Issue `json:"issues,omitempty"`
+	// Path, RolledBack, Unrestored and BackupDir describe a failure to
+	// write the files, see initpkg.CommitError
+	Path       string   `json:"path,omitempty"`
+	RolledBack []string `json:"rolled_back,omitempty"`
+	Unrestored []string `json:"unrestored,omitempty"`
+	BackupDir  string   `json:"backup_dir,omitempty"`
+}
+
 var initCmd = &cobra.Command{
-	Use:   "init [directory]",
+	Use:   "init",
 	Short: "Initialize a new FionaCode project",
+	// -C may name a directory that doesn't exist yet
+	Annotations: map[string]string{annotationCreateTarget: "true"},
 	Long: `Initialize a new FionaCode project by copying opencode.json and .opencode directory.
 
-If no directory is specified, initializes in the current directory.
-If a directory is specified, it will be created if it doesn't exist.`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		var targetDir string
+Initializes the current directory, or the directory given with -C, which is
+created if it doesn't exist. A positional directory argument is still
+accepted but deprecated.
+
+With --merge, an existing opencode.json is kept and any agents, tools and MCP
+servers it is missing are added from the FionaCode configuration. Entries that
+already exist are never overwritten; conflicting definitions are reported.
+
+With --force, existing files are overwritten. Every file that is replaced is
+first copied into a timestamped .opencode.bak-YYYYMMDD-HHMMSS/ directory
+unless --no-backup is given. Files are replaced all or nothing: if one can't
+be written, every file already updated is restored, the files involved are
+listed and init exits with status 1.
+
+With --into, fifi initializes inside an existing project such as an app
+repository. Instead of refusing to run because opencode.json or .opencode
+exist, it only checks the files it is about to create and fails, listing
+them, if any already exists. Files it doesn't create are never touched;
+.gitignore and .env.example only get entries appended.
+
+With --dry-run, the files init would write are listed with what would happen
+to each (create, overwrite, merge, keep or conflict) and nothing is written.
+It also checks that the target directory is writable. The command exits with
+status 1 if init would fail because of a conflict or a read-only target.
+
+With --from, the files are read from a local template directory containing
+opencode.json, .opencode/prompts/ and .opencode/tool/ instead of the
+configuration embedded in fifi.
+
+--from also accepts a GitHub repository as github:owner/repo[/dir][@ref],
+e.g. "fifi init --from github:acme/fionacode-template@v2". The repository
+tarball is downloaded into the user cache directory and reused on later runs;
+use --refresh-template to download it again. dir selects a template inside
+the repository and ref defaults to the default branch. Set
+FIFI_GITHUB_CODELOAD to download from a GitHub Enterprise host.
+
+With --only, just the listed components (config, prompts, tool) are written,
+e.g. "fifi init --only tool" recreates .opencode/tool/ without touching
+opencode.json or the prompts.
+
+Entries for .env files and local state are added to .gitignore (creating it if
+needed); existing lines are kept. Use --gitignore=false to leave it alone.
+
+With --preset, a bundled profile is scaffolded instead of the full
+configuration: "minimal" copies the orchestrator with the planning,
+implementer and file-navigator agents, their prompts and tools, and no MCP
+servers. The default is "full".
+
+With --agents, opencode.json only keeps the listed agents, e.g.
+"fifi init --agents orchestrator,docs", and only the prompt files they
+reference are written. Tools and MCP servers are unaffected. Every agent must
+exist in the configuration (after --preset is applied).
+
+With --minimal-json, opencode.json is rewritten with 2-space indentation and
+a trailing newline rather than copied byte for byte, so every project starts
+from identically formatted config.
+
+With --output (-o), nothing is written to disk except a gzipped tarball of
+the files init would create, e.g. "fifi init -o project.tar.gz". Entry names
+are relative to the project root, so extract it with
+"tar -xzf project.tar.gz -C my-project". --merge and --force can't be
+combined with --output, and the tarball isn't validated.
+
+With --hook, a shell command is run in the project directory once init has
+fully succeeded, e.g. --hook "git init". The flag can be repeated; hooks run
+in order and the first failing one makes init exit with an error, keeping
+the scaffolded files. FIFI_TARGET_DIR is set to the absolute project
+directory and FIFI_PRESET to the preset used.
+
+With --manifest, the SHA256 of opencode.json and every prompt and tool file
+is recorded in .opencode/manifest.json together with the fifi version, so
+"fifi verify" can later report files that were changed.
+
+With --env-example, every environment variable the MCP servers in
+opencode.json use is listed in .env.example with a blank value.
+
+With --json, the outcome is printed as a JSON object instead of the usual
+report: the project directory, the path of opencode.json and the prompt and
+tool files created. On failure a JSON object with an "error" field is written
+to stderr and the command exits with status 1. Hook output goes to stderr.
+
+The freshly created project is validated before init reports success. Use
+--no-post-validate to skip this check. Validation is skipped with --only since
+a partial scaffold isn't a complete project on its own.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDirectory,
+	RunE: func(cmd *cobra.Command, args []string) (err error) {
+		if initJSON {
+			defer func() {
+				var exitErr *exitError
+				if err != nil && !errors.As(err, &exitErr) {
+					err = initJSONError(cmd, initErrorReport{Error: err.Error()})
+				}
+			}()
+		}
+
+		// A -C directory that doesn't exist yet is created by Initialize,
+		// which removes it again if init fails
+		targetDir := missingTarget
 		if len(args) > 0 {
 			targetDir = args[0]
+			warnDeprecatedDirArg(cmd, targetDir)
 		}
 
-		fmt.Printf("Initializing FionaCode project")
-		if targetDir != "" {
-			fmt.Printf(" in %s", targetDir)
-		} else {
-			fmt.Printf(" in current directory")
+		var only []initpkg.Component
+		for _, name := range onlyComponents {
+			component, err := initpkg.ParseComponent(name)
+			if err != nil {
+				return fmt.Errorf("invalid --only value: %w", err)
+			}
+			only = append(only, component)
+		}
+
+		var source initpkg.Source
+		if templateDir != "" {
+			dir, err := resolveTemplateDir(cmd.Context(), templateDir, refreshTemplate)
+			if err != nil {
+				return fmt.Errorf("invalid --from value: %w", err)
+			}
+			source, err = initpkg.NewDirSource(dir)
+			if err != nil {
+				return fmt.Errorf("invalid --from value: %w", err)
+			}
+		}
+
+		opts := initpkg.Options{
+			Merge:         mergeInit,
+			Force:         forceInit,
+			Into:          initInto,
+			NoBackup:      noBackup,
+			Only:          only,
+			Source:        source,
+			GitIgnore:     writeGitignore,
+			EnvExample:    writeEnvExample,
+			Preset:        initPreset,
+			Agents:        initAgents,
+			NormalizeJSON: minimalJSON,
+			Manifest:      writeManifest,
+			Version:       Version,
+		}
+
+		if initOutput != "" {
+			return initArchive(initOutput, opts)
+		}
+
+		if initJSON {
+			return initWithJSON(cmd, targetDir, opts)
+		}
+
+		if initDryRun {
+			return initPlan(cmd, targetDir, opts)
 		}
-		fmt.Println("...")
 
-		if err := initpkg.Initialize(targetDir); err != nil {
+		fmt.Printf("Initializing FionaCode project in %s...\n", displayTarget(targetDir))
+
+		result, err := initpkg.Initialize(targetDir, opts)
+		var commitErr *initpkg.CommitError
+		if errors.As(err, &commitErr) {
+			return reportCommitError(cmd, commitErr)
+		}
+		if err != nil {
 			return fmt.Errorf("initialization failed: %w", err)
 		}
 
-		fmt.Println("\n✓ Successfully initialized FionaCode project!")
+		postValidate := !skipPostValidate && len(only) == 0
+		if postValidate {
+			issues, err := validate.Validate(targetDir, validate.Options{})
+			if err != nil {
+				return fmt.Errorf("initialized project failed validation: %w", err)
+			}
+			if validate.HasErrors(issues) {
+				printIssues(issues)
+				return fmt.Errorf("initialized project failed validation: %s", countIssues(issues))
+			}
+		}
+
+		fmt.Printf("\n%s Successfully initialized FionaCode project!\n", okMark())
+		if postValidate {
+			fmt.Printf("%s Configuration is valid!\n", okMark())
+		}
 		fmt.Println("\nCreated:")
-		fmt.Println("  - opencode.json")
-		fmt.Println("  - .opencode/prompts/ (14 files)")
-		fmt.Println("  - .opencode/tool/ (20 files)")
+		if includesComponent(only, initpkg.ComponentConfig) {
+			if result.Merged {
+				fmt.Println("  - opencode.json (merged into existing file)")
+			} else {
+				fmt.Println("  - opencode.json")
+			}
+		}
+		if includesComponent(only, initpkg.ComponentPrompts) {
+			fmt.Printf("  - .opencode/prompts/ (%d files)\n", result.PromptFiles)
+		}
+		if includesComponent(only, initpkg.ComponentTool) {
+			fmt.Printf("  - .opencode/tool/ (%d files)\n", result.ToolFiles)
+		}
+		if result.GitIgnoreUpdated {
+			fmt.Println("  - .gitignore entries for .env and local state")
+		}
+		if len(result.EnvExampleVars) > 0 {
+			fmt.Printf("  - .env.example (%d variables)\n", len(result.EnvExampleVars))
+		}
+		if writeManifest {
+			fmt.Printf("  - %s\n", initpkg.ManifestFile)
+		}
+
+		if printFiles {
+			fmt.Println("\nFiles written:")
+			for _, file := range result.Files {
+				fmt.Printf("  %s\n", file)
+			}
+		}
+
+		if result.BackupDir != "" {
+			fmt.Printf("\nBacked up overwritten files to %s\n", result.BackupDir)
+		}
+
+		if len(result.Conflicts) > 0 {
+			fmt.Println("\nSkipped conflicting entries (kept your definition):")
+			for _, conflict := range result.Conflicts {
+				fmt.Printf("  - %s\n", conflict)
+			}
+		}
+
+		if len(initHooks) > 0 {
+			dir := targetDir
+			if dir == "" {
+				dir = "."
+			}
+			preset := initPreset
+			if preset == "" {
+				preset = assets.DefaultPreset
+			}
+			// The project is fine; only the hook's failure is worth showing
+			if err := runHooks(dir, initHooks, preset, os.Stdout); err != nil {
+				return withExitCode(cmd, 1, err)
+			}
+		}
+
+		opencodeInstalled := opencodeCheck().ok
+		if !opencodeInstalled {
+			fmt.Fprintf(os.Stderr, "\n%s opencode is not on PATH; install it before running the project\n", warningMarkFor(os.Stderr))
+		}
+
 		fmt.Println("\nNext steps:")
 		fmt.Println("  1. Review and customize opencode.json")
-		fmt.Println("  2. Set up your API keys in environment variables")
-		fmt.Println("  3. Run: opencode")
+		if writeEnvExample {
+			fmt.Println("  2. Copy .env.example to .env and set your API keys")
+		} else {
+			fmt.Println("  2. Set up your API keys in environment variables")
+		}
+		if opencodeInstalled {
+			fmt.Println("  3. Run: opencode")
+		} else {
+			fmt.Printf("  3. Install OpenCode: %s\n", opencodeInstallCommand)
+			fmt.Println("  4. Run: opencode")
+		}
 		fmt.Println("\nFor more information, visit: https://github.com/dscv103/fionacode")
 
 		return nil
 	},
 }
 
+// reportCommitError lists which files were rolled back and which couldn't
+// be restored after init failed to move its files into place, so a failed
+// --force never silently leaves a half-updated project
+func reportCommitError(cmd *cobra.Command, commitErr *initpkg.CommitError) error {
+	fmt.Fprintf(os.Stderr, "%s initialization failed: %v\n", errorMarkFor(os.Stderr), commitErr.Err)
+	if len(commitErr.RolledBack) > 0 {
+		fmt.Fprintln(os.Stderr, "\nRolled back (unchanged):")
+		for _, path := range commitErr.RolledBack {
+			fmt.Fprintf(os.Stderr, "  - %s\n", path)
+		}
+	}
+	if len(commitErr.Unrestored) > 0 {
+		fmt.Fprintf(os.Stderr, "\nCould not be restored (previous versions are in %s):\n", commitErr.BackupDir)
+		for _, path := range commitErr.Unrestored {
+			fmt.Fprintf(os.Stderr, "  - %s\n", path)
+		}
+		return withExitCode(cmd, 1, fmt.Errorf("\n%d file(s) were left modified", len(commitErr.Unrestored)))
+	}
+	return withExitCode(cmd, 1, fmt.Errorf("\nthe project was left as it was"))
+}
+
+// initWithJSON runs init like the default output does and reports the
+// outcome as JSON
+func initWithJSON(cmd *cobra.Command, targetDir string, opts initpkg.Options) error {
+	result, err := initpkg.Initialize(targetDir, opts)
+	var commitErr *initpkg.CommitError
+	if errors.As(err, &commitErr) {
+		return initJSONError(cmd, initErrorReport{
+			Error:      fmt.Sprintf("initialization failed: %v", commitErr.Err),
+			Path:       commitErr.Path,
+			RolledBack: commitErr.RolledBack,
+			Unrestored: commitErr.Unrestored,
+			BackupDir:  commitErr.BackupDir,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("initialization failed: %w", err)
+	}
+
+	if !skipPostValidate && len(opts.Only) == 0 {
+		issues, err := validate.Validate(targetDir, validate.Options{})
+		if err != nil {
+			return fmt.Errorf("initialized project failed validation: %w", err)
+		}
+		if validate.HasErrors(issues) {
+			return initJSONError(cmd, initErrorReport{
+				Error:  fmt.Sprintf("initialized project failed validation: %s", countIssues(issues)),
+				Issues: issues,
+			})
+		}
+	}
+
+	dir := targetDir
+	if dir == "" {
+		dir = "."
+	}
+	if len(initHooks) > 0 {
+		preset := opts.Preset
+		if preset == "" {
+			preset = assets.DefaultPreset
+		}
+		if err := runHooks(dir, initHooks, preset, os.Stderr); err != nil {
+			return initJSONError(cmd, initErrorReport{Error: err.Error()})
+		}
+	}
+
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	report := initReport{
+		Directory: dir,
+		Config:    validate.ConfigPath(dir),
+		Merged:    result.Merged,
+		Prompts:   []string{},
+		Tools:     []string{},
+		Files:     result.Files,
+		BackupDir: result.BackupDir,
+		Conflicts: result.Conflicts,
+	}
+	promptsDir := filepath.Join(dir, ".opencode", "prompts") + string(filepath.Separator)
+	toolDir := filepath.Join(dir, ".opencode", "tool") + string(filepath.Separator)
+	for _, file := range result.Files {
+		switch {
+		case strings.HasPrefix(file, promptsDir):
+			report.Prompts = append(report.Prompts, file)
+		case strings.HasPrefix(file, toolDir):
+			report.Tools = append(report.Tools, file)
+		}
+	}
+	return writeJSON(report)
+}
+
+// initJSONError prints report to stderr and makes init exit with status 1
+func initJSONError(cmd *cobra.Command, report initErrorReport) error {
+	if err := writeJSONTo(os.Stderr, report); err != nil {
+		return err
+	}
+	return withExitCode(cmd, 1, nil)
+}
+
+// initArchive writes the project init would create to a gzipped tarball at
+// output. The tarball is written next to output and renamed into place, so
+// a failed run never leaves a truncated archive behind.
+func initArchive(output string, opts initpkg.Options) (err error) {
+	tmp, err := os.CreateTemp(filepath.Dir(output), ".fifi-archive-*")
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", output, err)
+	}
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmp.Name())
+		}
+	}()
+
+	result, err := initpkg.Archive(tmp, opts)
+	if err != nil {
+		return fmt.Errorf("initialization failed: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+	if err := os.Rename(tmp.Name(), output); err != nil {
+		return fmt.Errorf("failed to write %s: %w", output, err)
+	}
+
+	fmt.Printf("%s Wrote FionaCode project to %s (%d files)\n", okMark(), output, len(result.Files))
+	if printFiles {
+		fmt.Println("\nFiles written:")
+		for _, file := range result.Files {
+			fmt.Printf("  %s\n", file)
+		}
+	}
+	return nil
+}
+
+// initPlan prints what init would do in targetDir without writing anything
+func initPlan(cmd *cobra.Command, targetDir string, opts initpkg.Options) error {
+	if targetDir == "" {
+		targetDir = "."
+	}
+	plan, err := initpkg.Plan(targetDir, opts)
+	if err != nil {
+		return fmt.Errorf("initialization failed: %w", err)
+	}
+
+	fmt.Println("Dry run: nothing will be written.")
+	fmt.Println()
+	counts := make(map[initpkg.Action]int)
+	for _, file := range plan {
+		fmt.Printf("  %-10s %s\n", file.Action, file.Path)
+		counts[file.Action]++
+	}
+
+	fmt.Printf("\n%d to create, %d to overwrite, %d to merge, %d to keep, %d conflicting\n",
+		counts[initpkg.ActionCreate], counts[initpkg.ActionOverwrite], counts[initpkg.ActionMerge],
+		counts[initpkg.ActionKeep], counts[initpkg.ActionConflict])
+
+	writeErr := initpkg.CheckWritable(targetDir)
+	if writeErr != nil {
+		fmt.Printf("%s %v\n", errorMark(), writeErr)
+	} else {
+		fmt.Printf("%s %s is writable\n", okMark(), targetDir)
+	}
+	if counts[initpkg.ActionConflict] > 0 {
+		hint := "use --into to only check the files init creates, --merge to keep existing files or --force to overwrite them"
+		if opts.Into {
+			hint = "use --merge to keep existing files or --force to overwrite them"
+		}
+		return withExitCode(cmd, 1, fmt.Errorf("init would fail: %s", hint))
+	}
+	if writeErr != nil {
+		return withExitCode(cmd, 1, fmt.Errorf("init would fail: %w", writeErr))
+	}
+	return nil
+}
+
+// includesComponent reports whether c is selected by an --only list
+func includesComponent(only []initpkg.Component, c initpkg.Component) bool {
+	if len(only) == 0 {
+		return true
+	}
+	for _, o := range only {
+		if o == c {
+			return true
+		}
+	}
+	return false
+}
+
 func init() {
+	initCmd.Flags().BoolVar(&mergeInit, "merge", false, "Merge into an existing opencode.json instead of failing")
+	initCmd.Flags().BoolVarP(&forceInit, "force", "f", false, "Overwrite existing opencode.json and .opencode files")
+	initCmd.Flags().BoolVar(&noBackup, "no-backup", false, "Don't back up files overwritten by --force")
+	initCmd.Flags().StringVar(&templateDir, "from", "", "Initialize from a local template directory or github:owner/repo[/dir][@ref] instead of the embedded configuration")
+	initCmd.Flags().BoolVar(&refreshTemplate, "refresh-template", false, "Download a github: --from template again instead of using the cached copy")
+	initCmd.Flags().StringSliceVar(&onlyComponents, "only", nil, "Only scaffold these components (comma-separated): config, prompts, tool")
+	initCmd.Flags().BoolVar(&writeGitignore, "gitignore", true, "Add .env and local state entries to .gitignore")
+	initCmd.Flags().BoolVar(&writeEnvExample, "env-example", false, "Write a .env.example listing the environment variables MCP servers use")
+	initCmd.Flags().StringVar(&initPreset, "preset", assets.DefaultPreset, "Bundled profile to scaffold: full or minimal")
+	initCmd.Flags().StringSliceVar(&initAgents, "agents", nil, "Only include these agents (comma-separated) and the prompt files they reference")
+	initCmd.Flags().BoolVar(&minimalJSON, "minimal-json", false, "Write opencode.json with normalized 2-space formatting")
+	initCmd.Flags().BoolVar(&writeManifest, "manifest", false, "Record the SHA256 of every created file in .opencode/manifest.json")
+	initCmd.Flags().BoolVar(&printFiles, "print-files", false, "List the absolute path of every file written")
+	initCmd.Flags().BoolVar(&skipPostValidate, "no-post-validate", false, "Skip validating the project after initialization")
+	initCmd.Flags().StringVarP(&initOutput, "output", "o", "", "Write the project to a gzipped tarball instead of a directory")
+	initCmd.Flags().StringArrayVar(&initHooks, "hook", nil, "Shell command to run in the project directory after a successful init (repeatable)")
+	initCmd.Flags().BoolVar(&initInto, "into", false, "Initialize inside an existing project, only failing if files init creates already exist")
+	initCmd.Flags().BoolVar(&initDryRun, "dry-run", false, "List the files init would write without writing anything")
+	initCmd.Flags().BoolVar(&initJSON, "json", false, "Print the result as JSON, and errors as JSON on stderr")
+	initCmd.MarkFlagsMutuallyExclusive("json", "output")
+	initCmd.MarkFlagsMutuallyExclusive("json", "dry-run")
+	initCmd.MarkFlagsMutuallyExclusive("json", "print-files")
+	initCmd.MarkFlagsMutuallyExclusive("into", "merge")
+	initCmd.MarkFlagsMutuallyExclusive("into", "force")
+	initCmd.MarkFlagsMutuallyExclusive("dry-run", "output")
+	initCmd.MarkFlagsMutuallyExclusive("dry-run", "hook")
+	initCmd.MarkFlagsMutuallyExclusive("output", "merge")
+	initCmd.MarkFlagsMutuallyExclusive("output", "hook")
+	initCmd.MarkFlagsMutuallyExclusive("output", "force")
+	initCmd.RegisterFlagCompletionFunc("only", completeComponents)
+	initCmd.RegisterFlagCompletionFunc("preset", completePresets)
+	initCmd.RegisterFlagCompletionFunc("agents", completeEmbeddedAgents)
+	initCmd.MarkFlagDirname("from")
 	rootCmd.AddCommand(initCmd)
 }
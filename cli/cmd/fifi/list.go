@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/assets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listLong bool
+	listHash bool
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the embedded configuration, prompts and tools",
+	Long: `List every file bundled in fifi that init would copy into a project.
+
+Output is sorted by path, one file per line. Use --long to also show each
+file's size in bytes.
+
+With --hash, each file's SHA256 is shown before its path, in the format of
+sha256sum, so files in an initialized project can be checked against the
+embedded originals:
+
+  fifi list --hash | sha256sum --check`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		files, err := embeddedFiles()
+		if err != nil {
+			return err
+		}
+
+		for _, file := range files {
+			name := strings.TrimPrefix(file, "embedded/")
+			if !listLong && !listHash {
+				fmt.Println(name)
+				continue
+			}
+
+			content, err := assets.ReadFile(file)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			var line string
+			if listLong {
+				line = fmt.Sprintf("%8d  ", len(content))
+			}
+			if listHash {
+				sum := sha256.Sum256(content)
+				line += hex.EncodeToString(sum[:]) + "  "
+			}
+			fmt.Println(line + name)
+		}
+
+		return nil
+	},
+}
+
+// embeddedFiles returns the asset paths of opencode.json and every embedded
+// prompt and tool file, sorted
+func embeddedFiles() ([]string, error) {
+	if _, err := assets.GetOpencodeJSON(); err != nil {
+		return nil, fmt.Errorf("failed to read embedded opencode.json: %w", err)
+	}
+
+	promptFiles, err := assets.GetPromptFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embedded prompts: %w", err)
+	}
+
+	toolFiles, err := assets.GetToolFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list embedded tools: %w", err)
+	}
+
+	files := append([]string{"embedded/opencode.json"}, promptFiles...)
+	files = append(files, toolFiles...)
+	sort.Strings(files)
+	return files, nil
+}
+
+func init() {
+	listCmd.Flags().BoolVarP(&listLong, "long", "l", false, "Show file sizes in bytes")
+	listCmd.Flags().BoolVar(&listHash, "hash", false, "Show the SHA256 of each file")
+	rootCmd.AddCommand(listCmd)
+}
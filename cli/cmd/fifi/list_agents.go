@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	listAgentsDetail bool
+)
+
+var listAgentsCmd = &cobra.Command{
+	Use:   "list-agents [directory]",
+	Short: "List the agents configured in opencode.json",
+	Long: `List the agents configured in a project's opencode.json, one name per line
+in sorted order.
+
+With --detail, each line also shows the agent's type, temperature, prompt
+path and number of tools:
+
+  NAME  TYPE  TEMPERATURE  PROMPT  TOOLS
+
+separated by whitespace, with "-" for missing values.
+
+If no directory is specified, the project in the current directory is listed.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDirectory,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		targetDir := "."
+		if len(args) > 0 {
+			targetDir = args[0]
+		}
+
+		config, err := validate.LoadConfig(targetDir)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(config.Agent))
+		for name := range config.Agent {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			if !listAgentsDetail {
+				fmt.Println(name)
+				continue
+			}
+
+			agent := config.Agent[name]
+			agentType := agent.Type
+			if agentType == "" {
+				agentType = agent.Mode
+			}
+			fmt.Printf("%-20s %-10s %-4g %-40s %d\n", name, orDash(agentType), agent.Temperature, orDash(agent.Prompt), len(agent.ToolNames()))
+		}
+		return nil
+	},
+}
+
+// orDash returns s, or "-" if it is empty, to keep columns aligned
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func init() {
+	listAgentsCmd.Flags().BoolVar(&listAgentsDetail, "detail", false, "Also show each agent's type, temperature, prompt and tool count")
+	rootCmd.AddCommand(listAgentsCmd)
+}
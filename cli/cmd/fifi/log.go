@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	initpkg "github.com/dscv103/fionacode/cli/internal/init"
+	"github.com/spf13/cobra"
+)
+
+var (
+	logLevel  string
+	logFormat string
+)
+
+// setupLogging installs the logger selected by --log-level and --log-format
+// as the default and hands it to the internal packages. Logs go to stderr.
+func setupLogging() error {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(logLevel)); err != nil {
+		return fmt.Errorf("invalid --log-level %q: use debug, info, warn or error", logLevel)
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch logFormat {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid --log-format %q: use text or json", logFormat)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	initpkg.SetLogger(logger)
+	return nil
+}
+
+// loggingTransport logs every HTTP request made through it at debug level
+type loggingTransport struct {
+	base http.RoundTripper
+}
+
+func (t loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		slog.Debug("http request failed", "method", req.Method, "url", req.URL.Redacted(), "duration", time.Since(start), "error", err)
+		return nil, err
+	}
+	slog.Debug("http request", "method", req.Method, "url", req.URL.Redacted(), "status", resp.StatusCode, "duration", time.Since(start))
+	return resp, nil
+}
+
+// completeLogLevels completes --log-level values
+func completeLogLevels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"debug", "info", "warn", "error"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLogFormats completes --log-format values
+func completeLogFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"text", "json"}, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -12,6 +13,13 @@ var (
 	Version = "dev"
 	// BuildDate is set during build via ldflags
 	BuildDate = "unknown"
+
+	// targetFlag is the directory given with -C/--target
+	targetFlag string
+	// missingTarget is set instead of changing into targetFlag when a
+	// command that creates its target names a directory that doesn't exist
+	// yet
+	missingTarget string
 )
 
 var rootCmd = &cobra.Command{
@@ -23,20 +31,124 @@ It packages the FionaCode configuration (opencode.json) and all associated
 prompts and tools, making it easy to start new projects with a proven
 multi-agent AI development framework.`,
 	Version: Version,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
-		// Check for updates (except for the update command itself to avoid recursion)
-		if cmd.Name() != "update" && cmd.Name() != "version" {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Like git -C, run as if started in the target directory
+		if targetFlag != "" && cmd.Annotations[annotationCreateTarget] != "" && !dirExists(targetFlag) {
+			// The command creates the target itself, so it can also remove
+			// it again if it fails
+			missingTarget = targetFlag
+		} else if targetFlag != "" {
+			if err := os.Chdir(targetFlag); err != nil {
+				return fmt.Errorf("cannot change to directory %s: %w", targetFlag, err)
+			}
+		}
+
+		// Config file defaults apply to every flag not given on the command line
+		if err := loadConfig(cmd); err != nil {
+			return err
+		}
+
+		// After the config file, which may set the log level
+		if err := setupLogging(); err != nil {
+			return err
+		}
+
+		// Check for updates (except for the update command itself to avoid
+		// recursion, and never while the shell is asking for completions)
+		switch cmd.Name() {
+		case "update", "version", "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		default:
 			checkForUpdates()
 		}
+		return nil
 	},
 }
 
 func init() {
 	rootCmd.SetVersionTemplate(fmt.Sprintf("fifi version %s (built %s)\n", Version, BuildDate))
+	rootCmd.PersistentFlags().StringVarP(&targetFlag, "target", "C", "", "Run as if fifi was started in this directory")
+	rootCmd.MarkPersistentFlagDirname("target")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "Log level: debug, info, warn or error")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: text or json")
+	rootCmd.RegisterFlagCompletionFunc("log-level", completeLogLevels)
+	rootCmd.RegisterFlagCompletionFunc("log-format", completeLogFormats)
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colors and Unicode symbols (also set by NO_COLOR)")
+}
+
+// annotationCreateTarget marks commands that create the -C directory
+// themselves if it doesn't exist; they find it in missingTarget
+const annotationCreateTarget = "fifi/create-target"
+
+// warnDeprecatedDirArg tells the user that the positional directory argument
+// of cmd is deprecated in favor of -C
+func warnDeprecatedDirArg(cmd *cobra.Command, dir string) {
+	fmt.Fprintf(os.Stderr, "%s the directory argument is deprecated; use 'fifi -C %s %s' instead\n", warningMarkFor(os.Stderr), dir, cmd.Name())
+}
+
+// exitError makes fifi exit with a specific status code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// isDryRun reports whether cmd was asked to only preview its changes
+func isDryRun(cmd *cobra.Command) bool {
+	flag := cmd.Flags().Lookup("dry-run")
+	return flag != nil && flag.Value.String() == "true"
+}
+
+// displayTarget names the directory a command works on for messages: dir
+// if given, else the -C directory, else the current directory
+func displayTarget(dir string) string {
+	switch {
+	case dir != "":
+		return dir
+	case targetFlag != "":
+		return targetFlag
+	default:
+		return "current directory"
+	}
+}
+
+// dirExists reports whether dir exists
+func dirExists(dir string) bool {
+	_, err := os.Stat(dir)
+	return err == nil
+}
+
+// withExitCode returns an error that makes fifi exit with code. err is
+// printed once by main without usage help; it may be nil when the command
+// has already reported the outcome itself.
+func withExitCode(cmd *cobra.Command, code int, err error) error {
+	cmd.SilenceUsage = true
+	cmd.SilenceErrors = true
+	return &exitError{code: code, err: err}
 }
 
 func main() {
+	// Remove the binary a previous update left behind (Windows only)
+	cleanupOldExecutable()
+
 	if err := rootCmd.Execute(); err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			if exitErr.err != nil {
+				fmt.Fprintln(os.Stderr, exitErr.err)
+			}
+			os.Exit(exitErr.code)
+		}
+
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
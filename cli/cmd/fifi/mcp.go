@@ -0,0 +1,356 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/jsonedit"
+	"github.com/dscv103/fionacode/cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	mcpAddCommand string
+	mcpAddArgs    []string
+	mcpAddURL     string
+	mcpAddEnv     []string
+)
+
+var mcpCmd = &cobra.Command{
+	Use:   "mcp",
+	Short: "List, add, remove and inspect MCP servers in opencode.json",
+	Long: `Manage the MCP servers configured in opencode.json.
+
+Servers are read from both the mcp section OpenCode uses and the mcpServers
+section. Edits only touch the server's entry; the rest of the file keeps its
+formatting and key order.
+
+Run from the project directory, or give it with -C.`,
+}
+
+var mcpListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configured MCP servers",
+	Long: `List the MCP servers configured in opencode.json in sorted order, with
+their transport and the command or URL they use:
+
+  NAME  TRANSPORT  COMMAND or URL
+
+The transport is "local" for servers started with a command and "remote"
+for servers reached over HTTP. Disabled servers are marked as such.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		config, err := validate.LoadConfig(".")
+		if err != nil {
+			return err
+		}
+
+		names := mcpServerNames(config)
+		if len(names) == 0 {
+			fmt.Println("No MCP servers configured")
+			return nil
+		}
+		for _, name := range names {
+			transport, target, enabled := describeMCPServer(config, name)
+			line := fmt.Sprintf("%-20s %-7s %s", name, transport, orDash(target))
+			if !enabled {
+				line += " (disabled)"
+			}
+			fmt.Println(line)
+		}
+		return nil
+	},
+}
+
+var mcpAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add an MCP server to opencode.json",
+	Long: `Add an MCP server to the mcp section of opencode.json, where OpenCode
+reads it from.
+
+A local server is started with --command, with --args passed to it (repeat
+--args for each argument); a remote server is reached at --url. Exactly one
+of --command and --url must be given. --env KEY=VALUE sets an environment
+variable for a local server and can be repeated.
+
+  fifi mcp add github --command npx --args -y --args @modelcontextprotocol/server-github --env 'GITHUB_TOKEN=${GITHUB_TOKEN}'
+  fifi mcp add docs --url https://mcp.example.com/sse
+
+The entry is validated before opencode.json is written, and a server that
+already exists is never replaced.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		if len(mcpAddArgs) > 0 && mcpAddCommand == "" {
+			return fmt.Errorf("--args requires --command")
+		}
+		if len(mcpAddEnv) > 0 && mcpAddCommand == "" {
+			return fmt.Errorf("--env requires --command; remote servers have no environment")
+		}
+		server := validate.MCP{Type: "remote", URL: mcpAddURL}
+		if mcpAddCommand != "" {
+			server = validate.MCP{Type: "local", Command: append([]string{mcpAddCommand}, mcpAddArgs...)}
+		}
+		for _, pair := range mcpAddEnv {
+			key, value, ok := strings.Cut(pair, "=")
+			if !ok || key == "" {
+				return fmt.Errorf("invalid --env value %q: expected KEY=VALUE", pair)
+			}
+			if server.Environment == nil {
+				server.Environment = make(map[string]string)
+			}
+			server.Environment[key] = value
+		}
+
+		if err := addMCPServer(".", name, server); err != nil {
+			return err
+		}
+		fmt.Printf("%s Added MCP server %s to opencode.json\n", okMark(), name)
+		return nil
+	},
+}
+
+// addMCPServer validates server and adds it to the mcp section of the
+// opencode.json in targetDir, refusing to replace an existing server
+func addMCPServer(targetDir, name string, server validate.MCP) error {
+	if err := server.Check(name); err != nil {
+		return err
+	}
+
+	config, err := validate.LoadConfig(targetDir)
+	if err != nil {
+		return err
+	}
+	if _, ok := config.MCP[name]; ok {
+		return fmt.Errorf("MCP server %q already exists in opencode.json", name)
+	}
+	if _, ok := config.MCPServers[name]; ok {
+		return fmt.Errorf("MCP server %q already exists in opencode.json", name)
+	}
+
+	return mutateConfig(targetDir, func(content []byte) ([]byte, error) {
+		updated, err := jsonedit.Set(content, server, "mcp", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add MCP server %q: %w", name, err)
+		}
+		return updated, nil
+	})
+}
+
+var mcpRemoveCmd = &cobra.Command{
+	Use:   "remove <name>",
+	Short: "Remove an MCP server from opencode.json",
+	Long: `Remove an MCP server from opencode.json, whether it is defined in the mcp or
+the mcpServers section.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeMCPServers,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		config, err := validate.LoadConfig(".")
+		if err != nil {
+			return err
+		}
+		var sections []string
+		if _, ok := config.MCP[name]; ok {
+			sections = append(sections, "mcp")
+		}
+		if _, ok := config.MCPServers[name]; ok {
+			sections = append(sections, "mcpServers")
+		}
+		if len(sections) == 0 {
+			return fmt.Errorf("MCP server %q not found in opencode.json", name)
+		}
+
+		err = mutateConfig(".", func(content []byte) ([]byte, error) {
+			for _, section := range sections {
+				var err error
+				content, err = jsonedit.Remove(content, section, name)
+				if err != nil {
+					return nil, fmt.Errorf("failed to remove MCP server %q: %w", name, err)
+				}
+			}
+			return content, nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s Removed MCP server %s from opencode.json\n", okMark(), name)
+		return nil
+	},
+}
+
+var mcpResolveCmd = &cobra.Command{
+	Use:   "resolve <name>",
+	Short: "Print an MCP server's command line with variables expanded",
+	Long: `Print the command line (or URL and headers) of an MCP server with its
+${VAR}, ${VAR:-default} and {env:VAR} references expanded, to show exactly
+what OpenCode will run.
+
+Variables are taken from the server's own environment settings first and
+then from the current environment. References that can't be resolved are
+printed as they are and listed as warnings.`,
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeMCPServers,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+
+		config, err := validate.LoadConfig(".")
+		if err != nil {
+			return err
+		}
+
+		var command []string
+		var url string
+		var headers, env map[string]string
+		if server, ok := config.MCP[name]; ok {
+			command, url, headers, env = server.Command, server.URL, server.Headers, server.Environment
+		} else if server, ok := config.MCPServers[name]; ok {
+			if server.Command != "" {
+				command = append([]string{server.Command}, server.Args...)
+			}
+			url, env = server.URL, server.Env
+		} else {
+			return fmt.Errorf("MCP server %q not found in opencode.json", name)
+		}
+
+		var unresolved []string
+		expand := func(value string, env map[string]string) string {
+			expanded, missing := validate.ExpandEnv(value, env)
+			for _, variable := range missing {
+				if !slices.Contains(unresolved, variable) {
+					unresolved = append(unresolved, variable)
+				}
+			}
+			return expanded
+		}
+
+		// The server's environment values may themselves refer to
+		// variables of the current environment
+		resolvedEnv := make(map[string]string, len(env))
+		for _, key := range sortedKeys(env) {
+			resolvedEnv[key] = expand(env[key], nil)
+		}
+
+		if len(command) > 0 {
+			expanded := make([]string, len(command))
+			for i, arg := range command {
+				expanded[i] = shellQuote(expand(arg, resolvedEnv))
+			}
+			fmt.Printf("Command: %s\n", strings.Join(expanded, " "))
+		}
+		if url != "" {
+			fmt.Printf("URL: %s\n", expand(url, resolvedEnv))
+		}
+		if len(headers) > 0 {
+			fmt.Println("Headers:")
+			for _, key := range sortedKeys(headers) {
+				fmt.Printf("  %s: %s\n", key, expand(headers[key], resolvedEnv))
+			}
+		}
+		if len(resolvedEnv) > 0 {
+			fmt.Println("Environment:")
+			for _, key := range sortedKeys(resolvedEnv) {
+				fmt.Printf("  %s=%s\n", key, resolvedEnv[key])
+			}
+		}
+
+		for _, variable := range unresolved {
+			fmt.Fprintf(os.Stderr, "%s environment variable %s is not set\n", warningMarkFor(os.Stderr), variable)
+		}
+		return nil
+	},
+}
+
+// shellQuote quotes arg for a POSIX shell if it contains anything but
+// plainly safe characters
+func shellQuote(arg string) string {
+	if arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./-_", r))
+	}) == -1 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mcpServerNames returns the names of the servers in both MCP sections,
+// sorted
+func mcpServerNames(config validate.OpencodeConfig) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range config.MCP {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range config.MCPServers {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// describeMCPServer returns the transport of the server called name, the
+// command line or URL it uses, and whether it is enabled. The mcp section
+// wins if both define it, as it does for OpenCode.
+func describeMCPServer(config validate.OpencodeConfig, name string) (transport, target string, enabled bool) {
+	if server, ok := config.MCP[name]; ok {
+		if server.URL != "" || server.Type == "remote" {
+			return "remote", server.URL, server.IsEnabled()
+		}
+		return "local", strings.Join(server.Command, " "), server.IsEnabled()
+	}
+
+	server := config.MCPServers[name]
+	if server.URL != "" {
+		return "remote", server.URL, true
+	}
+	return "local", strings.TrimSpace(server.Command + " " + strings.Join(server.Args, " ")), true
+}
+
+// completeMCPServers completes the MCP servers of the opencode.json in the
+// current directory
+func completeMCPServers(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	config, err := validate.LoadConfig(".")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, name := range mcpServerNames(config) {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	mcpAddCmd.Flags().StringVar(&mcpAddCommand, "command", "", "Command that starts a local server")
+	mcpAddCmd.Flags().StringArrayVar(&mcpAddArgs, "args", nil, "Argument passed to --command (repeatable)")
+	mcpAddCmd.Flags().StringVar(&mcpAddURL, "url", "", "URL of a remote server")
+	mcpAddCmd.Flags().StringArrayVar(&mcpAddEnv, "env", nil, "Environment variable for the server as KEY=VALUE (repeatable)")
+	mcpAddCmd.MarkFlagsMutuallyExclusive("command", "url")
+	mcpAddCmd.MarkFlagsOneRequired("command", "url")
+	mcpAddCmd.RegisterFlagCompletionFunc("url", cobra.NoFileCompletions)
+
+	mcpCmd.AddCommand(mcpListCmd, mcpAddCmd, mcpRemoveCmd, mcpResolveCmd)
+	rootCmd.AddCommand(mcpCmd)
+}
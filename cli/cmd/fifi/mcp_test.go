@@ -0,0 +1,108 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+)
+
+func TestAddMCPServer(t *testing.T) {
+	const config = `{
+  "$schema": "https://opencode.ai/config.json",
+  "mcp": {
+    "fs": {
+      "type": "local",
+      "command": ["npx", "-y", "server-filesystem"]
+    }
+  }
+}
+`
+
+	tests := []struct {
+		name    string
+		server  validate.MCP
+		wantErr string
+	}{
+		{
+			name: "local server",
+			server: validate.MCP{
+				Type:        "local",
+				Command:     []string{"npx", "-y", "server-github"},
+				Environment: map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}"},
+			},
+		},
+		{
+			name:   "remote server",
+			server: validate.MCP{Type: "remote", URL: "https://mcp.example.com/sse"},
+		},
+		{
+			name:    "invalid url",
+			server:  validate.MCP{Type: "remote", URL: "ftp://mcp.example.com"},
+			wantErr: "invalid url",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "opencode.json")
+			if err := os.WriteFile(path, []byte(config), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := addMCPServer(dir, "new", tt.server)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("addMCPServer() error = %v, want %q", err, tt.wantErr)
+				}
+				content, _ := os.ReadFile(path)
+				if string(content) != config {
+					t.Errorf("opencode.json changed after a rejected server:\n%s", content)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("addMCPServer() error = %v", err)
+			}
+
+			loaded, err := validate.LoadConfig(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := loaded.MCP["new"]; !reflect.DeepEqual(got, tt.server) {
+				t.Errorf("mcp.new = %+v, want %+v", got, tt.server)
+			}
+			if _, ok := loaded.MCP["fs"]; !ok {
+				t.Error("existing server fs was lost")
+			}
+
+			var raw map[string]json.RawMessage
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(content, &raw); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := raw["mcpServers"]; ok {
+				t.Error("server was written to mcpServers instead of mcp")
+			}
+		})
+	}
+
+	t.Run("existing server", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "opencode.json"), []byte(config), 0644); err != nil {
+			t.Fatal(err)
+		}
+		err := addMCPServer(dir, "fs", validate.MCP{Type: "remote", URL: "https://mcp.example.com"})
+		if err == nil || !strings.Contains(err.Error(), "already exists") {
+			t.Fatalf("addMCPServer() error = %v, want an already exists error", err)
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// noColor is set by the persistent --no-color flag
+var noColor bool
+
+const (
+	ansiReset  = "\x1b[0m"
+	ansiRed    = "\x1b[31m"
+	ansiGreen  = "\x1b[32m"
+	ansiYellow = "\x1b[33m"
+)
+
+// plainOutput reports whether output to f should stick to plain ASCII
+// without colors: when --no-color or NO_COLOR is set, or f isn't a terminal
+// (e.g. piped to a file or captured by CI)
+func plainOutput(f *os.File) bool {
+	return noColor || os.Getenv("NO_COLOR") != "" || !isTerminal(f)
+}
+
+// mark returns symbol in color for terminals and fallback otherwise
+func mark(f *os.File, symbol, color, fallback string) string {
+	if plainOutput(f) {
+		return fallback
+	}
+	return color + symbol + ansiReset
+}
+
+// okMark is the marker printed before a success message on stdout
+func okMark() string {
+	return mark(os.Stdout, "✓", ansiGreen, "OK")
+}
+
+// errorMark is the marker printed before an error on stdout
+func errorMark() string {
+	return errorMarkFor(os.Stdout)
+}
+
+// errorMarkFor is the marker printed before an error on f
+func errorMarkFor(f *os.File) string {
+	return mark(f, "✗", ansiRed, "x")
+}
+
+// warningMark is the marker printed before a warning on stdout
+func warningMark() string {
+	return warningMarkFor(os.Stdout)
+}
+
+// warningMarkFor is the marker printed before a warning on f
+func warningMarkFor(f *os.File) string {
+	return mark(f, "!", ansiYellow, "!")
+}
+
+// printBanner prints lines framed in a box on terminals and as plain lines
+// otherwise
+func printBanner(f *os.File, lines ...string) {
+	if plainOutput(f) {
+		for _, line := range lines {
+			if line = strings.TrimSpace(line); line != "" {
+				fmt.Fprintln(f, line)
+			}
+		}
+		return
+	}
+
+	const width = 48
+	fmt.Fprintf(f, "╭%s╮\n", strings.Repeat("─", width))
+	for _, line := range lines {
+		fmt.Fprintf(f, "│  %-*s│\n", width-2, line)
+	}
+	fmt.Fprintf(f, "╰%s╯\n", strings.Repeat("─", width))
+}
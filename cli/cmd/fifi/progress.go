@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// isTerminal reports whether f is attached to a terminal
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// progressReader reports how much of a download has been read
+type progressReader struct {
+	r       io.Reader
+	out     io.Writer
+	total   int64
+	read    int64
+	lastLog time.Time
+}
+
+// newProgressReader wraps r and draws a progress bar on out. total is the
+// expected size in bytes, or -1 if unknown.
+func newProgressReader(r io.Reader, total int64, out io.Writer) *progressReader {
+	return &progressReader{r: r, out: out, total: total}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+
+	// Redraw at most ten times a second, and always at the end
+	if err == io.EOF || time.Since(p.lastLog) >= 100*time.Millisecond {
+		p.lastLog = time.Now()
+		p.draw()
+	}
+	return n, err
+}
+
+func (p *progressReader) draw() {
+	if p.total <= 0 {
+		fmt.Fprintf(p.out, "\r  %s downloaded", formatBytes(p.read))
+		return
+	}
+
+	const width = 30
+	percent := float64(p.read) / float64(p.total)
+	if percent > 1 {
+		percent = 1
+	}
+	filled := int(percent * width)
+	fmt.Fprintf(p.out, "\r  [%s%s] %3.0f%% (%s / %s)",
+		strings.Repeat("=", filled), strings.Repeat(" ", width-filled),
+		percent*100, formatBytes(p.read), formatBytes(p.total))
+}
+
+// Finish ends the progress line
+func (p *progressReader) Finish() {
+	p.draw()
+	fmt.Fprintln(p.out)
+}
+
+// formatBytes renders a byte count as B, KB or MB
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/jsonedit"
+	"github.com/dscv103/fionacode/cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var (
+	purgeAgent bool
+)
+
+var removeAgentCmd = &cobra.Command{
+	Use:   "remove-agent <name> [directory]",
+	Short: "Remove an agent from opencode.json",
+	Long: `Remove an agent from the agent section of opencode.json.
+
+Only the agent's entry is removed; the rest of the file keeps its formatting
+and key order so the change shows up as a small diff.
+
+With --purge, the agent's prompt file is deleted as well, provided it lives in
+.opencode/prompts/ and no other agent uses it.
+
+If no directory is specified, the project in the current directory is used.`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeRemoveAgentArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		targetDir := "."
+		if len(args) > 1 {
+			targetDir = args[1]
+		}
+
+		config, err := validate.LoadConfig(targetDir)
+		if err != nil {
+			return err
+		}
+		agent, ok := config.Agent[name]
+		if !ok {
+			return fmt.Errorf("agent %q not found in opencode.json", name)
+		}
+
+		err = mutateConfig(targetDir, func(content []byte) ([]byte, error) {
+			updated, err := jsonedit.Remove(content, "agent", name)
+			if err != nil {
+				return nil, fmt.Errorf("failed to remove agent %q: %w", name, err)
+			}
+			return updated, nil
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s Removed agent %s from opencode.json\n", okMark(), name)
+
+		if !purgeAgent || agent.Prompt == "" {
+			return nil
+		}
+
+		delete(config.Agent, name)
+		removed, reason := purgePrompt(targetDir, agent.Prompt, config)
+		if !removed {
+			fmt.Printf("%s Kept prompt %s: %s\n", warningMark(), agent.Prompt, reason)
+			return nil
+		}
+		fmt.Printf("%s Removed prompt %s\n", okMark(), agent.Prompt)
+		return nil
+	},
+}
+
+// purgePrompt deletes a removed agent's prompt file if it is inside
+// .opencode/prompts/ and none of the remaining agents use it. When the file
+// is kept, the reason is returned.
+func purgePrompt(targetDir, prompt string, remaining validate.OpencodeConfig) (bool, string) {
+	rel := filepath.Clean(filepath.FromSlash(prompt))
+	promptsDir := filepath.Join(".opencode", "prompts")
+	if !strings.HasPrefix(rel, promptsDir+string(filepath.Separator)) {
+		return false, "it is outside .opencode/prompts/"
+	}
+
+	for other, agent := range remaining.Agent {
+		if agent.Prompt != "" && filepath.Clean(filepath.FromSlash(agent.Prompt)) == rel {
+			return false, fmt.Sprintf("it is still used by agent %s", other)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(targetDir, rel)); err != nil {
+		if os.IsNotExist(err) {
+			return false, "it doesn't exist"
+		}
+		return false, err.Error()
+	}
+	return true, ""
+}
+
+// completeRemoveAgentArgs completes agent names from the opencode.json in
+// the current directory, then a directory
+func completeRemoveAgentArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return completeDirectory(cmd, args[1:], toComplete)
+	}
+	config, err := validate.LoadConfig(".")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(config.Agent))
+	for name := range config.Agent {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	removeAgentCmd.Flags().BoolVar(&purgeAgent, "purge", false, "Also delete the agent's prompt file if no other agent uses it")
+	rootCmd.AddCommand(removeAgentCmd)
+}
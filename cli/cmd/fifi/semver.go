@@ -0,0 +1,53 @@
+package main
+
+import (
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// canonicalVersion returns a release tag or version in the "v1.2.3" form the
+// semver package compares, or "" if it isn't a semantic version
+func canonicalVersion(version string) string {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if !semver.IsValid(version) {
+		return ""
+	}
+	return version
+}
+
+// newestRelease returns the release with the highest semantic version,
+// skipping drafts, tags that aren't semantic versions and, unless
+// prerelease is set, prereleases. It returns nil if none is left.
+func newestRelease(releases []releaseInfo, prerelease bool) *releaseInfo {
+	var newest *releaseInfo
+	for i := range releases {
+		release := &releases[i]
+		version := canonicalVersion(release.TagName)
+		if release.Draft || version == "" {
+			continue
+		}
+		if !prerelease && (release.Prerelease || semver.Prerelease(version) != "") {
+			continue
+		}
+		if newest == nil || semver.Compare(version, canonicalVersion(newest.TagName)) > 0 {
+			newest = release
+		}
+	}
+	return newest
+}
+
+// isNewerVersion reports whether latest is a newer release than current.
+// A current version that isn't a semantic version, such as "dev" for a
+// local build, is older than any release; a latest version that isn't one
+// is never newer.
+func isNewerVersion(latest, current string) bool {
+	latest = canonicalVersion(latest)
+	if latest == "" {
+		return false
+	}
+	current = canonicalVersion(current)
+	return current == "" || semver.Compare(latest, current) > 0
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"archive/tar"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// githubTemplatePrefix marks a --from value naming a GitHub repository
+const githubTemplatePrefix = "github:"
+
+// defaultGitHubCodeload is the tarball host used unless FIFI_GITHUB_CODELOAD is set
+const defaultGitHubCodeload = "https://codeload.github.com"
+
+// githubTemplate is a parsed github:owner/repo[/dir][@ref] template
+type githubTemplate struct {
+	owner, repo string
+	// dir is the slash-separated template directory inside the repository
+	dir string
+	ref string
+}
+
+// parseGitHubTemplate parses the part of a --from value after "github:".
+// The ref defaults to HEAD, the repository's default branch.
+func parseGitHubTemplate(spec string) (githubTemplate, error) {
+	t := githubTemplate{ref: "HEAD"}
+	if i := strings.LastIndex(spec, "@"); i >= 0 {
+		t.ref = spec[i+1:]
+		spec = spec[:i]
+	}
+
+	parts := strings.SplitN(strings.Trim(spec, "/"), "/", 3)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" || t.ref == "" {
+		return t, fmt.Errorf("expected github:owner/repo[/dir][@ref], got %q", githubTemplatePrefix+spec)
+	}
+	t.owner, t.repo = parts[0], parts[1]
+	if len(parts) == 3 {
+		t.dir = path.Clean(parts[2])
+		if !filepath.IsLocal(filepath.FromSlash(t.dir)) {
+			return t, fmt.Errorf("invalid template directory %q", parts[2])
+		}
+	}
+	return t, nil
+}
+
+// tarballURL returns the codeload URL of the repository at the template's ref
+func (t githubTemplate) tarballURL() string {
+	base := defaultGitHubCodeload
+	if env := os.Getenv("FIFI_GITHUB_CODELOAD"); env != "" {
+		base = strings.TrimRight(env, "/")
+	}
+	return fmt.Sprintf("%s/%s/%s/tar.gz/%s", base, url.PathEscape(t.owner), url.PathEscape(t.repo), url.PathEscape(t.ref))
+}
+
+// templateCacheDir returns <user cache dir>/fifi/templates/<owner>/<repo>/<ref>
+func templateCacheDir(t githubTemplate) (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fifi", "templates", t.owner, t.repo, url.PathEscape(t.ref)), nil
+}
+
+// resolveTemplateDir returns the local directory of a --from value. GitHub
+// templates are downloaded into the user cache dir, or reused from there
+// unless refresh is set; local directories are returned unchanged.
+func resolveTemplateDir(ctx context.Context, from string, refresh bool) (string, error) {
+	spec, ok := strings.CutPrefix(from, githubTemplatePrefix)
+	if !ok {
+		return from, nil
+	}
+
+	t, err := parseGitHubTemplate(spec)
+	if err != nil {
+		return "", err
+	}
+	cacheDir, err := templateCacheDir(t)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate cache directory: %w", err)
+	}
+	templateDir := filepath.Join(cacheDir, filepath.FromSlash(t.dir))
+
+	if _, err := os.Stat(cacheDir); err == nil && !refresh {
+		return templateDir, nil
+	}
+
+	fmt.Printf("Downloading template %s/%s@%s...\n", t.owner, t.repo, t.ref)
+	if err := downloadTemplate(ctx, t, cacheDir); err != nil {
+		return "", fmt.Errorf("failed to download template: %w", err)
+	}
+	return templateDir, nil
+}
+
+// downloadTemplate downloads the repository tarball of t and extracts it
+// into cacheDir, replacing an earlier download only once extraction succeeded
+func downloadTemplate(ctx context.Context, t githubTemplate, cacheDir string) error {
+	if err := os.MkdirAll(filepath.Dir(cacheDir), 0755); err != nil {
+		return err
+	}
+
+	archive, err := os.CreateTemp(filepath.Dir(cacheDir), ".template-*.tar.gz")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	err = withDownloadRetry(ctx, "download template", func(ctx context.Context) error {
+		return downloadTemplateTarball(ctx, t.tarballURL(), archive)
+	})
+	if err != nil {
+		return err
+	}
+	if err := archive.Close(); err != nil {
+		return err
+	}
+
+	extractDir, err := os.MkdirTemp(filepath.Dir(cacheDir), ".template-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(extractDir)
+
+	if err := extractTemplate(archive.Name(), extractDir); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(cacheDir); err != nil {
+		return err
+	}
+	return os.Rename(extractDir, cacheDir)
+}
+
+// downloadTemplateTarball downloads url into file, replacing any partial
+// content from an earlier attempt. A GitHub token, if configured, is sent
+// so private repositories can be used.
+func downloadTemplateTarball(ctx context.Context, url string, file *os.File) error {
+	if err := file.Truncate(0); err != nil {
+		return permanent(err)
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return permanent(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return permanent(err)
+	}
+	if token := githubToken(); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client, err := updateClient()
+	if err != nil {
+		return permanent(err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return permanent(fmt.Errorf("repository or ref not found (%s)", url))
+	}
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp, fmt.Errorf("%s returned status %d", url, resp.StatusCode))
+	}
+
+	_, err = io.Copy(file, watchStall(ctx, resp.Body))
+	return err
+}
+
+// extractTemplate extracts the regular files of a repository tarball into
+// dir, dropping the top-level directory GitHub wraps them in. Executable
+// files stay executable.
+func extractTemplate(archivePath, dir string) error {
+	return walkTarGz(archivePath, func(name string, header *tar.Header, r io.Reader) (bool, error) {
+		// Drop the <repo>-<sha>/ prefix
+		_, rel, ok := strings.Cut(filepath.ToSlash(name), "/")
+		if !ok || rel == "" {
+			return true, nil
+		}
+
+		dest := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return false, err
+		}
+
+		perm := os.FileMode(0644)
+		if header.Mode&0111 != 0 {
+			perm = 0755
+		}
+		out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+		if err != nil {
+			return false, err
+		}
+		if _, err := io.Copy(out, r); err != nil {
+			out.Close()
+			return false, err
+		}
+		return true, out.Close()
+	})
+}
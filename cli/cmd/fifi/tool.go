@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/dscv103/fionacode/cli/internal/jsonedit"
+	"github.com/dscv103/fionacode/cli/internal/validate"
+	"github.com/spf13/cobra"
+)
+
+var toolCmd = &cobra.Command{
+	Use:   "tool",
+	Short: "Enable or disable tools in opencode.json",
+	Long: `Enable or disable tools in the tools section of opencode.json.
+
+Only the tool's entry is changed; the rest of the file keeps its formatting and
+key order. A tool that isn't listed yet is added.`,
+}
+
+var toolEnableCmd = &cobra.Command{
+	Use:   "enable <name> [directory]",
+	Short: "Enable a tool in opencode.json",
+	Long: `Set a tool to true in the tools section of opencode.json, adding it if it isn't
+listed yet.
+
+If no directory is specified, the project in the current directory is used.`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeToolArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTool(args, true)
+	},
+}
+
+var toolDisableCmd = &cobra.Command{
+	Use:   "disable <name> [directory]",
+	Short: "Disable a tool in opencode.json",
+	Long: `Set a tool to false in the tools section of opencode.json, adding it if it isn't
+listed yet.
+
+If no directory is specified, the project in the current directory is used.`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeToolArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return setTool(args, false)
+	},
+}
+
+// setTool sets tools.<name> in opencode.json to enabled, leaving the rest of
+// the file untouched
+func setTool(args []string, enabled bool) error {
+	name := args[0]
+	targetDir := "."
+	if len(args) > 1 {
+		targetDir = args[1]
+	}
+
+	state := "disabled"
+	if enabled {
+		state = "enabled"
+	}
+
+	config, err := validate.LoadConfig(targetDir)
+	if err != nil {
+		return err
+	}
+	if current, ok := config.Tools[name]; ok && current == enabled {
+		fmt.Printf("Tool %s is already %s\n", name, state)
+		return nil
+	}
+
+	err = mutateConfig(targetDir, func(content []byte) ([]byte, error) {
+		updated, err := jsonedit.Set(content, enabled, "tools", name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update tool %q: %w", name, err)
+		}
+		return updated, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s Tool %s %s\n", okMark(), name, state)
+	return nil
+}
+
+// completeToolArgs completes the tools listed in the opencode.json in the
+// current directory, then a directory
+func completeToolArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return completeDirectory(cmd, args[1:], toComplete)
+	}
+	config, err := validate.LoadConfig(".")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	names := make([]string, 0, len(config.Tools))
+	for name := range config.Tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	toolCmd.AddCommand(toolEnableCmd)
+	toolCmd.AddCommand(toolDisableCmd)
+	rootCmd.AddCommand(toolCmd)
+}
@@ -3,21 +3,42 @@ package main
 import (
 	"archive/tar"
 	"archive/zip"
+	"bufio"
 	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 const (
-	githubReleasesAPI = "https://api.github.com/repos/dscv103/fionacode/releases/latest"
+	// exitUpdateAvailable is the exit code of "update --check" when a newer release exists
+	exitUpdateAvailable = 10
+)
+
+var (
+	updateCheckOnly    bool
+	updateToVersion    string
+	updatePrerelease   bool
+	updateKeepDownload bool
+	updateDryRun       bool
+	allowDowngrade     bool
+	updateRetries      int
+	updateTimeout      time.Duration
 )
 
 var updateCmd = &cobra.Command{
@@ -26,11 +47,59 @@ var updateCmd = &cobra.Command{
 	Long: `Update fifi CLI to the latest version from GitHub releases.
 
 This command will download the latest version for your platform and replace
-the current binary. Requires write access to the fifi installation directory.`,
+the current binary. Requires write access to the fifi installation directory.
+
+With --version, install a specific release instead of the latest one, e.g.
+"fifi update --version v1.2.3". Installing a release older than the current
+version is refused unless --allow-downgrade is given, so a typo can't
+downgrade fifi by accident.
+Without it, fifi only ever updates to a release with a higher version.
+
+With --prerelease, prereleases are considered too and fifi updates to the
+release with the highest version, whether it's a prerelease or not. This is
+how beta testers track prerelease builds.
+
+With --check, only report whether a newer version exists without downloading
+anything. The command exits with status 10 when an update is available and 0
+when fifi is up to date, so scripts can gate on it.
+
+With --dry-run, the release asset for this platform is resolved and its name,
+download URL and the executable it would replace are printed, but nothing is
+downloaded or installed. Use it to check asset naming after a change to the
+release pipeline.
+
+Each request for release metadata is aborted if it doesn't complete within
+--timeout, and a download is aborted once it has received no data for that
+long (0 disables both limits). Press Ctrl-C to cancel an update in progress.
+
+Downloads are kept as a .part file in the user cache directory until they
+complete, so an interrupted update resumes where it stopped the next time it
+is run, if the server supports range requests.
+
+With --keep-download, the downloaded archive is left in the cache directory
+instead of being removed, whether the update succeeds or fails, and its path
+is printed. Use it to inspect or manually extract a problematic release asset.
+
+To update from a mirror whose certificate is signed by an internal CA, set
+FIFI_CA_BUNDLE to a PEM file with the CA certificates. They are trusted in
+addition to the system roots; certificate verification is never disabled.`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		maxRetries = updateRetries
+		requestTimeout = updateTimeout
+
+		// Cancel in-flight requests on Ctrl-C
+		ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
 		fmt.Println("Checking for updates...")
 
-		latestRelease, err := getLatestRelease()
+		var latestRelease *releaseInfo
+		var err error
+		if updateToVersion != "" {
+			latestRelease, err = getReleaseByTag(ctx, updateToVersion)
+		} else {
+			latestRelease, err = getNewestRelease(ctx, updatePrerelease)
+		}
 		if err != nil {
 			return fmt.Errorf("failed to check for updates: %w", err)
 		}
@@ -39,56 +108,229 @@ the current binary. Requires write access to the fifi installation directory.`,
 		currentVersion := strings.TrimPrefix(Version, "v")
 
 		if currentVersion == latestVersion {
-			fmt.Printf("✓ You're already on the latest version (v%s)\n", currentVersion)
+			if updateToVersion != "" {
+				fmt.Printf("%s You're already on v%s\n", okMark(), currentVersion)
+			} else {
+				fmt.Printf("%s You're already on the latest version (v%s)\n", okMark(), currentVersion)
+			}
+			return nil
+		}
+		if updateToVersion != "" && !allowDowngrade && isNewerVersion(currentVersion, latestVersion) {
+			return withExitCode(cmd, 1, fmt.Errorf("v%s is older than the installed v%s; use --allow-downgrade to install it anyway", latestVersion, currentVersion))
+		}
+		if updateToVersion == "" && !isNewerVersion(latestVersion, currentVersion) {
+			// Never "update" to an older release
+			fmt.Printf("%s You're on v%s, which is newer than the latest release (v%s)\n", okMark(), currentVersion, latestVersion)
 			return nil
 		}
 
 		fmt.Printf("Current version: v%s\n", currentVersion)
-		fmt.Printf("Latest version:  v%s\n", latestVersion)
-		fmt.Println("\nDownloading update...")
+		if updateToVersion != "" {
+			fmt.Printf("Target version:  v%s\n", latestVersion)
+		} else {
+			fmt.Printf("Latest version:  v%s\n", latestVersion)
+		}
+
+		if updateCheckOnly {
+			fmt.Println("\nAn update is available. Run: fifi update")
+			return withExitCode(cmd, exitUpdateAvailable, nil)
+		}
 
 		asset, err := findAssetForPlatform(latestRelease, latestVersion)
 		if err != nil {
 			return fmt.Errorf("update failed: %w", err)
 		}
 
-		if err := downloadAndInstall(asset); err != nil {
+		if updateDryRun {
+			exePath, err := executablePath()
+			if err != nil {
+				return fmt.Errorf("update failed: %w", err)
+			}
+			fmt.Println("\nDry run: nothing will be downloaded or installed.")
+			fmt.Printf("  Asset:   %s\n", asset.Name)
+			fmt.Printf("  URL:     %s\n", asset.BrowserDownloadURL)
+			fmt.Printf("  Replace: %s\n", exePath)
+			return nil
+		}
+
+		fmt.Println("\nDownloading update...")
+
+		if err := downloadAndInstall(ctx, latestRelease, asset); err != nil {
 			return fmt.Errorf("update failed: %w", err)
 		}
 
-		fmt.Printf("\n✓ Successfully updated to v%s!\n", latestVersion)
+		fmt.Printf("\n%s Successfully updated to v%s!\n", okMark(), latestVersion)
 		return nil
 	},
 }
 
 func init() {
+	updateCmd.Flags().StringVar(&updateToVersion, "version", "", "Install a specific release tag (e.g. v1.2.3) instead of the latest")
+	updateCmd.Flags().IntVar(&updateRetries, "retries", 3, "Retry failed downloads this many times with exponential backoff")
+	updateCmd.Flags().DurationVar(&updateTimeout, "timeout", 30*time.Second, "Abort a metadata request that takes longer than this, or a download that stalls for this long (0 for no limit)")
+	updateCmd.Flags().BoolVar(&updateCheckOnly, "check", false, "Only report whether an update is available (exit code 10 if so)")
+	updateCmd.Flags().BoolVar(&updatePrerelease, "prerelease", false, "Include prereleases when looking for the newest release")
+	updateCmd.Flags().BoolVar(&allowDowngrade, "allow-downgrade", false, "Allow --version to install a release older than the current one")
+	updateCmd.MarkFlagsMutuallyExclusive("version", "prerelease")
+	updateCmd.Flags().BoolVar(&updateDryRun, "dry-run", false, "Show the asset that would be downloaded and the executable it would replace")
+	updateCmd.MarkFlagsMutuallyExclusive("dry-run", "check")
+	updateCmd.Flags().BoolVar(&updateKeepDownload, "keep-download", false, "Keep the downloaded release archive and print its path")
+	updateCmd.RegisterFlagCompletionFunc("version", cobra.NoFileCompletions)
 	rootCmd.AddCommand(updateCmd)
 }
 
 type releaseAsset struct {
 	Name               string `json:"name"`
 	BrowserDownloadURL string `json:"browser_download_url"`
+	Size               int64  `json:"size"`
 }
 
 type releaseInfo struct {
-	TagName string         `json:"tag_name"`
-	Assets  []releaseAsset `json:"assets"`
+	TagName    string         `json:"tag_name"`
+	Draft      bool           `json:"draft"`
+	Prerelease bool           `json:"prerelease"`
+	Assets     []releaseAsset `json:"assets"`
 }
 
 // getLatestRelease fetches the latest release metadata (tag + assets) from GitHub API
-func getLatestRelease() (*releaseInfo, error) {
-	resp, err := http.Get(githubReleasesAPI)
+func getLatestRelease(ctx context.Context) (*releaseInfo, error) {
+	release, err := getRelease(ctx, githubReleasesAPI()+"/latest")
+	if errors.Is(err, errReleaseNotFound) {
+		// GitHub answers 404 when nothing has been released yet
+		return nil, fmt.Errorf("no releases found for %s", githubRepo)
+	}
+	return release, err
+}
+
+// getNewestRelease fetches the latest release, or with prerelease, the
+// release with the highest version including prereleases. GitHub's latest
+// release never is a prerelease, so those are found by listing every release.
+func getNewestRelease(ctx context.Context, prerelease bool) (*releaseInfo, error) {
+	if !prerelease {
+		return getLatestRelease(ctx)
+	}
+
+	releases, err := listReleases(ctx, true)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
+	release := newestRelease(releases, true)
+	if release == nil {
+		return nil, fmt.Errorf("no releases found for %s", githubRepo)
+	}
+	return release, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("GitHub API returned status %d", resp.StatusCode)
+// getReleaseByTag fetches the metadata of a specific release, listing the
+// available tags if it doesn't exist
+func getReleaseByTag(ctx context.Context, tag string) (*releaseInfo, error) {
+	if !strings.HasPrefix(tag, "v") {
+		tag = "v" + tag
 	}
 
+	release, err := getRelease(ctx, githubReleasesAPI()+"/tags/"+tag)
+	if errors.Is(err, errReleaseNotFound) {
+		tags, listErr := listReleaseTags(ctx)
+		if listErr != nil || len(tags) == 0 {
+			return nil, fmt.Errorf("release %s not found", tag)
+		}
+		return nil, fmt.Errorf("release %s not found (available: %s)", tag, strings.Join(tags, ", "))
+	}
+	return release, err
+}
+
+// listReleaseTags returns the tags of the most recent releases
+func listReleaseTags(ctx context.Context) ([]string, error) {
+	releases, err := listReleases(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]string, 0, len(releases))
+	for _, r := range releases {
+		tags = append(tags, r.TagName)
+	}
+	return tags, nil
+}
+
+// releasesPerPage is the largest page size the GitHub API allows, used
+// when listing every release
+const releasesPerPage = 100
+
+// listReleases returns the most recent releases, newest first. With all, it
+// follows the API's pagination to return every release.
+func listReleases(ctx context.Context, all bool) ([]releaseInfo, error) {
+	var releases []releaseInfo
+	url := githubReleasesAPI()
+	if all {
+		url = fmt.Sprintf("%s?per_page=%d", url, releasesPerPage)
+	}
+	for url != "" {
+		var page []releaseInfo
+		var next string
+		err := withRetry(ctx, "list releases", func(ctx context.Context) error {
+			resp, err := githubGet(ctx, url)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				return statusError(resp, githubStatusError(resp))
+			}
+
+			next = nextPageURL(resp.Header.Get("Link"))
+			return json.NewDecoder(resp.Body).Decode(&page)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		releases = append(releases, page...)
+		if !all {
+			break
+		}
+		url = next
+	}
+	return releases, nil
+}
+
+// nextPageURL returns the rel="next" URL of a GitHub Link header, or "" on
+// the last page
+func nextPageURL(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		url, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+		if !ok || !strings.Contains(params, `rel="next"`) {
+			continue
+		}
+		return strings.Trim(strings.TrimSpace(url), "<>")
+	}
+	return ""
+}
+
+// errReleaseNotFound is returned by getRelease when GitHub responds with 404
+var errReleaseNotFound = errors.New("release not found")
+
+// getRelease fetches release metadata from a GitHub releases API URL
+func getRelease(ctx context.Context, url string) (*releaseInfo, error) {
 	var release releaseInfo
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+	err := withRetry(ctx, "fetch release metadata", func(ctx context.Context) error {
+		resp, err := githubGet(ctx, url)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode == http.StatusNotFound {
+			return permanent(errReleaseNotFound)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return statusError(resp, githubStatusError(resp))
+		}
+
+		return json.NewDecoder(resp.Body).Decode(&release)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -100,8 +342,8 @@ func getLatestRelease() (*releaseInfo, error) {
 }
 
 // getLatestVersion is kept for lightweight version checks elsewhere
-func getLatestVersion() (string, error) {
-	release, err := getLatestRelease()
+func getLatestVersion(ctx context.Context) (string, error) {
+	release, err := getLatestRelease(ctx)
 	if err != nil {
 		return "", err
 	}
@@ -111,32 +353,55 @@ func getLatestVersion() (string, error) {
 // findAssetForPlatform selects the correct release asset for the current OS/arch.
 // Falls back to legacy naming (e.g., fifi_Linux_x86_64) for older releases.
 func findAssetForPlatform(release *releaseInfo, version string) (*releaseAsset, error) {
-	osSlug := runtime.GOOS
-	archSlug := runtime.GOARCH
+	return findAsset(release, version, runtime.GOOS, runtime.GOARCH)
+}
 
-	switch osSlug {
-	case "darwin":
-		osSlug = "macOS"
-	case "linux":
-		osSlug = "linux"
-	case "windows":
-		osSlug = "windows"
-	}
+// osAliases lists the names a GOOS may appear under in asset names,
+// preferred name first
+var osAliases = map[string][]string{
+	"darwin":  {"macOS", "darwin"},
+	"linux":   {"linux"},
+	"windows": {"windows"},
+}
 
-	legacyOS := strings.ToUpper(osSlug[:1]) + osSlug[1:]
-	legacyArch := archSlug
-	if archSlug == "amd64" {
-		legacyArch = "x86_64"
+// archAliases lists the names a GOARCH may appear under in asset names,
+// preferred name first
+var archAliases = map[string][]string{
+	"amd64": {"amd64", "x86_64"},
+	"arm64": {"arm64", "aarch64"},
+}
+
+// findAsset selects the release asset for goos/goarch, trying every naming
+// variant of the OS (e.g. macOS or darwin) and architecture (e.g. arm64 or
+// aarch64)
+func findAsset(release *releaseInfo, version, goos, goarch string) (*releaseAsset, error) {
+	osNames := osAliases[goos]
+	if osNames == nil {
+		osNames = []string{goos}
+	}
+	archNames := archAliases[goarch]
+	if archNames == nil {
+		archNames = []string{goarch}
 	}
 
-	candidates := []string{
-		fmt.Sprintf("fifi_%s_%s_%s.tar.gz", version, osSlug, archSlug),
-		fmt.Sprintf("fifi_%s_%s_%s.zip", version, osSlug, archSlug),
-		fmt.Sprintf("fifi_%s_%s_%s.tar.gz", "v"+version, osSlug, archSlug),
-		fmt.Sprintf("fifi_%s_%s_%s.zip", "v"+version, osSlug, archSlug),
-		fmt.Sprintf("fifi_%s_%s", legacyOS, legacyArch),
-		fmt.Sprintf("fifi_%s_%s.tar.gz", legacyOS, legacyArch),
-		fmt.Sprintf("fifi_%s_%s.zip", legacyOS, legacyArch),
+	var candidates []string
+	for _, v := range []string{version, "v" + version} {
+		for _, osName := range osNames {
+			for _, arch := range archNames {
+				candidates = append(candidates,
+					fmt.Sprintf("fifi_%s_%s_%s.tar.gz", v, osName, arch),
+					fmt.Sprintf("fifi_%s_%s_%s.zip", v, osName, arch))
+			}
+		}
+	}
+	for _, osName := range osNames {
+		legacyOS := strings.ToUpper(osName[:1]) + osName[1:]
+		for _, arch := range archNames {
+			candidates = append(candidates,
+				fmt.Sprintf("fifi_%s_%s", legacyOS, arch),
+				fmt.Sprintf("fifi_%s_%s.tar.gz", legacyOS, arch),
+				fmt.Sprintf("fifi_%s_%s.zip", legacyOS, arch))
+		}
 	}
 
 	for _, candidate := range candidates {
@@ -148,9 +413,7 @@ func findAssetForPlatform(release *releaseInfo, version string) (*releaseAsset,
 	}
 
 	for i := range release.Assets {
-		name := strings.ToLower(release.Assets[i].Name)
-		if strings.Contains(name, strings.ToLower(osSlug)) &&
-			(strings.Contains(name, strings.ToLower(archSlug)) || strings.Contains(name, strings.ToLower(legacyArch))) {
+		if assetFieldsMatch(release.Assets[i].Name, version, osNames, archNames) {
 			return &release.Assets[i], nil
 		}
 	}
@@ -160,11 +423,75 @@ func findAssetForPlatform(release *releaseInfo, version string) (*releaseAsset,
 		names = append(names, a.Name)
 	}
 
-	return nil, fmt.Errorf("no matching asset for %s/%s in release %s (assets: %s)", runtime.GOOS, runtime.GOARCH, release.TagName, strings.Join(names, ", "))
+	return nil, fmt.Errorf("no matching asset for %s/%s in release %s (assets: %s)", goos, goarch, release.TagName, strings.Join(names, ", "))
 }
 
-// downloadAndInstall downloads the binary for the current platform and replaces the current one
-func downloadAndInstall(asset *releaseAsset) error {
+// assetFieldsMatch reports whether the asset called name, split into
+// "_"-separated fields after its extension, has a field naming one of
+// osNames and one naming one of archNames. A field that looks like a version
+// must be version, so an asset of another release is never picked.
+func assetFieldsMatch(name, version string, osNames, archNames []string) bool {
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip", ".exe"} {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			name = name[:len(name)-len(ext)]
+			break
+		}
+	}
+	fields := strings.Split(name, "_")
+	if len(fields) < 3 || !strings.EqualFold(fields[0], "fifi") {
+		return false
+	}
+
+	var hasOS, hasArch bool
+	for _, field := range fields[1:] {
+		switch {
+		case isVersionField(field):
+			if strings.TrimPrefix(field, "v") != version {
+				return false
+			}
+		case containsFold(osNames, field):
+			hasOS = true
+		case containsFold(archNames, field):
+			hasArch = true
+		}
+	}
+	return hasOS && hasArch
+}
+
+// isVersionField reports whether an asset name field is a version such as
+// 1.2.0 or v1.2.0-rc.1
+func isVersionField(field string) bool {
+	field = strings.TrimPrefix(field, "v")
+	return field != "" && field[0] >= '0' && field[0] <= '9' && strings.Contains(field, ".")
+}
+
+// containsFold reports whether names contains s, ignoring case
+func containsFold(names []string, s string) bool {
+	for _, name := range names {
+		if strings.EqualFold(name, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// executablePath returns the path of the running fifi binary with symlinks
+// resolved, which is the file an update replaces
+func executablePath() (string, error) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+	exePath, err = filepath.EvalSymlinks(exePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve symlinks: %w", err)
+	}
+	return exePath, nil
+}
+
+// downloadAndInstall downloads the binary for the current platform, verifies
+// it against the release checksums and replaces the current one
+func downloadAndInstall(ctx context.Context, release *releaseInfo, asset *releaseAsset) error {
 	if asset == nil {
 		return fmt.Errorf("no release asset provided")
 	}
@@ -176,43 +503,53 @@ func downloadAndInstall(asset *releaseAsset) error {
 		tmpPattern = "fifi-update-*.zip"
 	}
 
-	// Get the path to the current executable
-	exePath, err := os.Executable()
+	exePath, err := executablePath()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return err
 	}
 
-	// Resolve symlinks
-	exePath, err = filepath.EvalSymlinks(exePath)
+	// Download into a .part file in the cache dir, continuing an earlier
+	// interrupted download of the same asset
+	partFile, err := openPartialDownload(release.TagName, asset.Name, tmpPattern)
 	if err != nil {
-		return fmt.Errorf("failed to resolve symlinks: %w", err)
+		return fmt.Errorf("failed to create temp file: %w", err)
 	}
-
-	// Download the archive
-	resp, err := http.Get(downloadURL)
+	partPath := partFile.Name()
+
+	// Download the archive, retrying dropped connections and server errors;
+	// each retry resumes from what was already written
+	err = withDownloadRetry(ctx, "download "+asset.Name, func(ctx context.Context) error {
+		return downloadToFile(ctx, downloadURL, partFile, asset.Size)
+	})
+	partFile.Close()
 	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
+		// Keep the .part file so the next run can resume
+		if updateKeepDownload {
+			fmt.Fprintf(os.Stderr, "Partial download kept at %s\n", partPath)
+		}
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("download failed with status %d. URL: %s", resp.StatusCode, downloadURL)
+	// The download is complete; drop the .part suffix so the asset's
+	// extension picks the right extractor
+	tmpPath := partPath
+	if trimmed, ok := strings.CutSuffix(partPath, ".part"); ok {
+		tmpPath = trimmed
+		if err := os.Rename(partPath, tmpPath); err != nil {
+			os.Remove(partPath)
+			return fmt.Errorf("failed to write temp file: %w", err)
+		}
 	}
-
-	// Create temporary file for archive (keep extension so we pick the right extractor)
-	tmpFile, err := os.CreateTemp("", tmpPattern)
-	if err != nil {
-		return fmt.Errorf("failed to create temp file: %w", err)
+	if updateKeepDownload {
+		defer fmt.Fprintf(os.Stderr, "Downloaded archive kept at %s\n", tmpPath)
+	} else {
+		defer os.Remove(tmpPath)
 	}
-	tmpPath := tmpFile.Name()
-	defer os.Remove(tmpPath)
 
-	// Write downloaded content to temp file
-	if _, err := io.Copy(tmpFile, resp.Body); err != nil {
-		tmpFile.Close()
-		return fmt.Errorf("failed to write temp file: %w", err)
+	// Verify the archive before touching anything on disk
+	if err := verifyAssetChecksum(ctx, release, asset, tmpPath); err != nil {
+		return err
 	}
-	tmpFile.Close()
 
 	// Extract binary from archive
 	binaryPath, err := extractBinary(tmpPath)
@@ -226,14 +563,219 @@ func downloadAndInstall(asset *releaseAsset) error {
 		return fmt.Errorf("failed to make binary executable: %w", err)
 	}
 
-	// Replace the current binary
-	// On Unix-like systems, we can rename while the file is in use
-	// On Windows, we may need a different approach
-	if err := os.Rename(binaryPath, exePath); err != nil {
-		// If rename fails, try copying
-		if err := copyFile(binaryPath, exePath); err != nil {
-			return fmt.Errorf("failed to replace binary: %w", err)
+	return installBinary(binaryPath, exePath, checkExecutable)
+}
+
+// installBinary replaces exePath with binaryPath and runs check on the
+// result. If check fails, the previous binary is restored from a backup taken
+// beforehand; the backup is removed unless restoring it failed.
+func installBinary(binaryPath, exePath string, check func(path string) error) error {
+	// Keep a copy of the current binary in case the new one doesn't run
+	backupPath := exePath + ".backup"
+	if err := copyFile(exePath, backupPath); err != nil {
+		return fmt.Errorf("failed to back up current binary: %w", err)
+	}
+	keepBackup := false
+	defer func() {
+		if !keepBackup {
+			os.Remove(backupPath)
+		}
+	}()
+
+	// Replace the current binary (platform-specific, see update_*.go)
+	if err := replaceExecutable(binaryPath, exePath); err != nil {
+		return fmt.Errorf("failed to replace binary: %w", err)
+	}
+
+	// Make sure the installed binary actually runs, restoring the backup if not
+	if err := check(exePath); err != nil {
+		if restoreErr := restoreBackup(backupPath, exePath); restoreErr != nil {
+			keepBackup = true
+			return fmt.Errorf("new binary failed to run (%v) and restoring the previous one failed: %w (backup kept at %s)", err, restoreErr, backupPath)
 		}
+		return fmt.Errorf("new binary failed to run, previous version restored: %w", err)
+	}
+
+	return nil
+}
+
+// restoreBackup puts the backup of the previous binary back at exePath. It
+// doesn't go through replaceExecutable: on Windows the running binary has
+// already been moved aside and can't be replaced again, but the broken new
+// binary at exePath isn't running and can simply be removed.
+func restoreBackup(backupPath, exePath string) error {
+	if err := os.Remove(exePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	if err := os.Rename(backupPath, exePath); err != nil {
+		return copyFile(backupPath, exePath)
+	}
+	return nil
+}
+
+// checkExecutable runs "<path> --version" to confirm a binary works
+func checkExecutable(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	if err != nil {
+		if len(out) > 0 {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+		}
+		return err
+	}
+	return nil
+}
+
+// downloadToFile downloads url into file. Content already in file from an
+// earlier attempt is kept and only the rest is requested with a range
+// request; if the server doesn't support ranges the download starts over.
+// size is the expected total size, or 0 if unknown.
+func downloadToFile(ctx context.Context, url string, file *os.File, size int64) error {
+	offset, err := file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return permanent(fmt.Errorf("failed to write temp file: %w", err))
+	}
+	if size > 0 && offset == size {
+		// Finished by an earlier run that was interrupted before installing
+		return nil
+	}
+	if size > 0 && offset > size {
+		if offset, err = restartDownload(file); err != nil {
+			return err
+		}
+	}
+
+	resp, err := httpGetFrom(ctx, url, offset)
+	if err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case offset > 0 && resp.StatusCode == http.StatusPartialContent:
+		fmt.Fprintf(os.Stderr, "Resuming download at %d bytes\n", offset)
+	case resp.StatusCode == http.StatusOK:
+		// The server ignored the range and sent everything
+		if offset > 0 {
+			if _, err := restartDownload(file); err != nil {
+				return err
+			}
+		}
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		// The partial file doesn't match the asset; retry from scratch
+		if _, err := restartDownload(file); err != nil {
+			return err
+		}
+		return fmt.Errorf("server rejected resuming the download; starting over")
+	default:
+		return statusError(resp, fmt.Errorf("download failed with status %d. URL: %s", resp.StatusCode, url))
+	}
+
+	// Show a progress bar when a person is watching
+	var body io.Reader = watchStall(ctx, resp.Body)
+	if isTerminal(os.Stderr) {
+		progress := newProgressReader(body, resp.ContentLength, os.Stderr)
+		defer progress.Finish()
+		body = progress
+	}
+
+	// Write downloaded content to temp file
+	if _, err := io.Copy(file, body); err != nil {
+		return fmt.Errorf("failed to download: %w", err)
+	}
+	return nil
+}
+
+// restartDownload discards a partial download so it starts from the beginning
+func restartDownload(file *os.File) (int64, error) {
+	if err := file.Truncate(0); err != nil {
+		return 0, permanent(fmt.Errorf("failed to write temp file: %w", err))
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return 0, permanent(fmt.Errorf("failed to write temp file: %w", err))
+	}
+	return 0, nil
+}
+
+// findChecksumsAsset returns the goreleaser checksums file of a release
+// (e.g. checksums.txt or fifi_<version>_checksums.txt)
+func findChecksumsAsset(release *releaseInfo) (*releaseAsset, error) {
+	for i := range release.Assets {
+		if strings.HasSuffix(strings.ToLower(release.Assets[i].Name), "checksums.txt") {
+			return &release.Assets[i], nil
+		}
+	}
+	return nil, fmt.Errorf("release %s has no checksums file", release.TagName)
+}
+
+// fetchChecksum downloads the checksums file and returns the SHA256 listed for assetName
+func fetchChecksum(ctx context.Context, checksums *releaseAsset, assetName string) (string, error) {
+	var lines []string
+	err := withRetry(ctx, "download "+checksums.Name, func(ctx context.Context) error {
+		resp, err := httpGet(ctx, checksums.BrowserDownloadURL)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", checksums.Name, err)
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return statusError(resp, fmt.Errorf("download of %s failed with status %d", checksums.Name, resp.StatusCode))
+		}
+
+		lines = nil
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", checksums.Name, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// Each line is "<sha256>  <file name>"
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == assetName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+
+	return "", fmt.Errorf("no checksum for %s in %s", assetName, checksums.Name)
+}
+
+// verifyAssetChecksum compares the SHA256 of the downloaded archive with
+// the one published in the release's checksums file
+func verifyAssetChecksum(ctx context.Context, release *releaseInfo, asset *releaseAsset, archivePath string) error {
+	checksums, err := findChecksumsAsset(release)
+	if err != nil {
+		return fmt.Errorf("cannot verify download: %w", err)
+	}
+
+	expected, err := fetchChecksum(ctx, checksums, asset.Name)
+	if err != nil {
+		return fmt.Errorf("cannot verify download: %w", err)
+	}
+
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return fmt.Errorf("failed to hash download: %w", err)
+	}
+
+	actual := hex.EncodeToString(hash.Sum(nil))
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", asset.Name, expected, actual)
 	}
 
 	return nil
@@ -247,17 +789,111 @@ func extractBinary(archivePath string) (string, error) {
 	return extractFromTarGz(archivePath)
 }
 
-// extractFromTarGz extracts the fifi binary from a tar.gz archive
-func extractFromTarGz(archivePath string) (string, error) {
-	file, err := os.Open(archivePath)
+// safeArchivePath cleans an archive entry name and rejects names that are
+// absolute or climb out of the extraction directory (zip-slip)
+func safeArchivePath(name string) (string, error) {
+	slashed := strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(slashed, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" {
+		return "", fmt.Errorf("archive entry %q has an absolute path", name)
+	}
+
+	cleaned := filepath.Clean(filepath.FromSlash(slashed))
+	for _, part := range strings.Split(filepath.ToSlash(cleaned), "/") {
+		if part == ".." {
+			return "", fmt.Errorf("archive entry %q escapes the extraction directory", name)
+		}
+	}
+
+	return cleaned, nil
+}
+
+// archiveEntry is a regular file in a release archive
+type archiveEntry struct {
+	// name is the cleaned path of the entry
+	name string
+	// executable is true for entries that look like a program
+	executable bool
+}
+
+// binaryNames returns the base names the fifi binary may have in a release
+// archive: the name of the running executable, then defaultName
+func binaryNames(defaultName string) []string {
+	exe, err := os.Executable()
+	if err != nil || exe == "" {
+		exe = os.Args[0]
+	}
+	base := filepath.Base(exe)
+	if filepath.Ext(defaultName) == ".exe" && filepath.Ext(base) != ".exe" {
+		base += ".exe"
+	}
+	if base == defaultName || base == "." || base == string(filepath.Separator) {
+		return []string{defaultName}
+	}
+	return []string{base, defaultName}
+}
+
+// pickBinaryEntry chooses the archive entry holding the fifi binary: the
+// first entry whose base name matches one of names, in order, or else the
+// only executable-looking file in the archive
+func pickBinaryEntry(entries []archiveEntry, names []string) (string, error) {
+	for _, want := range names {
+		for _, entry := range entries {
+			if filepath.Base(entry.name) == want {
+				return entry.name, nil
+			}
+		}
+	}
+
+	var executables []string
+	for _, entry := range entries {
+		if entry.executable {
+			executables = append(executables, entry.name)
+		}
+	}
+	switch len(executables) {
+	case 1:
+		return executables[0], nil
+	case 0:
+		return "", fmt.Errorf("%s binary not found in archive", names[len(names)-1])
+	default:
+		return "", fmt.Errorf("%s binary not found in archive and it holds several executables: %s",
+			names[len(names)-1], strings.Join(executables, ", "))
+	}
+}
+
+// writeTempBinary copies an extracted binary to a new temporary file and
+// returns its path
+func writeTempBinary(r io.Reader, pattern string) (string, error) {
+	tmpFile, err := os.CreateTemp("", pattern)
 	if err != nil {
 		return "", err
 	}
+	tmpPath := tmpFile.Name()
+
+	if _, err := io.Copy(tmpFile, r); err != nil {
+		tmpFile.Close()
+		os.Remove(tmpPath)
+		return "", err
+	}
+	if err := tmpFile.Close(); err != nil {
+		os.Remove(tmpPath)
+		return "", err
+	}
+	return tmpPath, nil
+}
+
+// walkTarGz calls fn for every regular file in a tar.gz archive with its
+// cleaned name, stopping early when fn returns false
+func walkTarGz(archivePath string, fn func(name string, header *tar.Header, r io.Reader) (bool, error)) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
 	defer file.Close()
 
 	gzr, err := gzip.NewReader(file)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer gzr.Close()
 
@@ -266,33 +902,58 @@ func extractFromTarGz(archivePath string) (string, error) {
 	for {
 		header, err := tr.Next()
 		if err == io.EOF {
-			break
+			return nil
 		}
 		if err != nil {
-			return "", err
+			return err
 		}
 
-		// Look for the fifi binary
-		if header.Name == "fifi" || filepath.Base(header.Name) == "fifi" {
-			// Create temp file for extracted binary
-			tmpFile, err := os.CreateTemp("", "fifi-binary-*")
-			if err != nil {
-				return "", err
-			}
-			tmpPath := tmpFile.Name()
-
-			if _, err := io.Copy(tmpFile, tr); err != nil {
-				tmpFile.Close()
-				os.Remove(tmpPath)
-				return "", err
-			}
-			tmpFile.Close()
+		name, err := safeArchivePath(header.Name)
+		if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
 
-			return tmpPath, nil
+		more, err := fn(name, header, tr)
+		if err != nil || !more {
+			return err
 		}
 	}
+}
+
+// extractFromTarGz extracts the fifi binary from a tar.gz archive
+func extractFromTarGz(archivePath string) (string, error) {
+	// Find the binary first, then extract it in a second pass since the
+	// archive can only be read front to back
+	var entries []archiveEntry
+	err := walkTarGz(archivePath, func(name string, header *tar.Header, r io.Reader) (bool, error) {
+		entries = append(entries, archiveEntry{name: name, executable: header.Mode&0111 != 0})
+		return true, nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	binary, err := pickBinaryEntry(entries, binaryNames("fifi"))
+	if err != nil {
+		return "", err
+	}
 
-	return "", fmt.Errorf("fifi binary not found in archive")
+	var tmpPath string
+	err = walkTarGz(archivePath, func(name string, header *tar.Header, r io.Reader) (bool, error) {
+		if name != binary {
+			return true, nil
+		}
+		var err error
+		tmpPath, err = writeTempBinary(r, "fifi-binary-*")
+		return false, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return tmpPath, nil
 }
 
 // extractFromZip extracts the fifi binary from a zip archive
@@ -303,34 +964,35 @@ func extractFromZip(archivePath string) (string, error) {
 	}
 	defer r.Close()
 
+	var entries []archiveEntry
+	files := make(map[string]*zip.File)
 	for _, f := range r.File {
-		// Look for the fifi.exe binary
-		if f.Name == "fifi.exe" || filepath.Base(f.Name) == "fifi.exe" {
-			rc, err := f.Open()
-			if err != nil {
-				return "", err
-			}
-			defer rc.Close()
-
-			// Create temp file for extracted binary
-			tmpFile, err := os.CreateTemp("", "fifi-binary-*.exe")
-			if err != nil {
-				return "", err
-			}
-			tmpPath := tmpFile.Name()
+		name, err := safeArchivePath(f.Name)
+		if err != nil {
+			return "", err
+		}
+		if !f.Mode().IsRegular() {
+			continue
+		}
+		entries = append(entries, archiveEntry{
+			name:       name,
+			executable: f.Mode()&0111 != 0 || strings.EqualFold(filepath.Ext(name), ".exe"),
+		})
+		files[name] = f
+	}
 
-			if _, err := io.Copy(tmpFile, rc); err != nil {
-				tmpFile.Close()
-				os.Remove(tmpPath)
-				return "", err
-			}
-			tmpFile.Close()
+	binary, err := pickBinaryEntry(entries, binaryNames("fifi.exe"))
+	if err != nil {
+		return "", err
+	}
 
-			return tmpPath, nil
-		}
+	rc, err := files[binary].Open()
+	if err != nil {
+		return "", err
 	}
+	defer rc.Close()
 
-	return "", fmt.Errorf("fifi.exe binary not found in archive")
+	return writeTempBinary(rc, "fifi-binary-*.exe")
 }
 
 // copyFile copies a file from src to dst
@@ -360,30 +1022,60 @@ func copyFile(src, dst string) error {
 	return os.Chmod(dst, sourceInfo.Mode())
 }
 
-// checkForUpdates checks if a newer version is available and prints a message
+// updateCheckEnabled reports whether the background update check should
+// run. It is off unless FIFI_UPDATE_CHECK=1 or "update-check: true" in the
+// config file opts in; FIFI_NO_UPDATE_CHECK=1 always turns it off. It never
+// runs when stdout or stderr isn't a terminal, so scripts and pipelines
+// don't make surprise network requests.
+func updateCheckEnabled() bool {
+	if os.Getenv("FIFI_NO_UPDATE_CHECK") == "1" {
+		return false
+	}
+	if !isTerminal(os.Stdout) || !isTerminal(os.Stderr) {
+		return false
+	}
+	if env, ok := os.LookupEnv("FIFI_UPDATE_CHECK"); ok {
+		return env == "1"
+	}
+	return settings.GetBool("update-check")
+}
+
+// checkForUpdates checks if a newer version is available and prints a
+// message, if enabled (see updateCheckEnabled).
+//
+// The result is cached for updateCheckTTL so most runs make no network call.
 func checkForUpdates() {
-	latestVersion, err := getLatestVersion()
-	if err != nil {
-		// Silently fail version check - don't interrupt user workflow
+	if !updateCheckEnabled() {
 		return
 	}
 
 	currentVersion := strings.TrimPrefix(Version, "v")
-	latestVersion = strings.TrimPrefix(latestVersion, "v")
-
-	if currentVersion == "dev" {
+	if canonicalVersion(currentVersion) == "" {
 		// Don't show update message for development builds
 		return
 	}
 
-	if currentVersion != latestVersion && latestVersion != "" {
+	latestVersion, ok := cachedLatestVersion()
+	if !ok {
+		var err error
+		latestVersion, err = getLatestVersion(context.Background())
+		if err != nil {
+			// Silently fail version check - don't interrupt user workflow
+			return
+		}
+		saveLatestVersion(latestVersion)
+	}
+
+	latestVersion = strings.TrimPrefix(latestVersion, "v")
+
+	if isNewerVersion(latestVersion, currentVersion) {
 		fmt.Fprintf(os.Stderr, "\n")
-		fmt.Fprintf(os.Stderr, "╭────────────────────────────────────────────────╮\n")
-		fmt.Fprintf(os.Stderr, "│  A new version of fifi is available!          │\n")
-		fmt.Fprintf(os.Stderr, "│  Current: v%-8s  Latest: v%-8s       │\n", currentVersion, latestVersion)
-		fmt.Fprintf(os.Stderr, "│                                                │\n")
-		fmt.Fprintf(os.Stderr, "│  Run: fifi update                              │\n")
-		fmt.Fprintf(os.Stderr, "╰────────────────────────────────────────────────╯\n")
+		printBanner(os.Stderr,
+			"A new version of fifi is available!",
+			fmt.Sprintf("Current: v%-8s  Latest: v%-8s", currentVersion, latestVersion),
+			"",
+			"Run: fifi update",
+		)
 		fmt.Fprintf(os.Stderr, "\n")
 	}
 }
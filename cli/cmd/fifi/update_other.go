@@ -0,0 +1,19 @@
+//go:build !windows
+
+package main
+
+import "os"
+
+// replaceExecutable moves the new binary over the current executable. On
+// Unix-like systems the running file can be replaced in place.
+func replaceExecutable(binaryPath, exePath string) error {
+	if err := os.Rename(binaryPath, exePath); err != nil {
+		// If rename fails (e.g. across filesystems), try copying
+		return copyFile(binaryPath, exePath)
+	}
+	return nil
+}
+
+// cleanupOldExecutable is a no-op outside Windows, where the previous
+// binary never needs to be moved aside
+func cleanupOldExecutable() {}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInstallBinary(t *testing.T) {
+	tests := []struct {
+		name     string
+		checkErr error
+		want     string
+		wantErr  bool
+	}{
+		{name: "new binary runs", want: "new"},
+		{name: "new binary fails", checkErr: errors.New("exec format error"), want: "old", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			exePath := filepath.Join(dir, "fifi")
+			binaryPath := filepath.Join(dir, "fifi.new")
+			if err := os.WriteFile(exePath, []byte("old"), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(binaryPath, []byte("new"), 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			var checked string
+			err := installBinary(binaryPath, exePath, func(path string) error {
+				checked = path
+				return tt.checkErr
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("installBinary() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if checked != exePath {
+				t.Errorf("checked %q, want %q", checked, exePath)
+			}
+
+			got, err := os.ReadFile(exePath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("installed binary = %q, want %q", got, tt.want)
+			}
+			if _, err := os.Stat(exePath + ".backup"); !errors.Is(err, os.ErrNotExist) {
+				t.Errorf("backup left behind: %v", err)
+			}
+		})
+	}
+}
+
+func TestRestoreBackup(t *testing.T) {
+	dir := t.TempDir()
+	exePath := filepath.Join(dir, "fifi.exe")
+	backupPath := exePath + ".backup"
+
+	// Like on Windows after replaceExecutable, the original has been moved
+	// aside and a broken binary sits at exePath
+	if err := os.WriteFile(filepath.Join(dir, "fifi.old.exe"), []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(exePath, []byte("broken"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(backupPath, []byte("old"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := restoreBackup(backupPath, exePath); err != nil {
+		t.Fatalf("restoreBackup() error = %v", err)
+	}
+	got, err := os.ReadFile(exePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "old" {
+		t.Errorf("restored binary = %q, want %q", got, "old")
+	}
+}
+
+func TestFindAsset(t *testing.T) {
+	// release returns a release with the named assets and a checksums file
+	release := func(names ...string) *releaseInfo {
+		r := &releaseInfo{TagName: "v1.2.0"}
+		for _, name := range append(names, "checksums.txt") {
+			r.Assets = append(r.Assets, releaseAsset{Name: name})
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		release *releaseInfo
+		goos    string
+		goarch  string
+		want    string
+	}{
+		{
+			name:    "macOS arm64",
+			release: release("fifi_1.2.0_linux_arm64.tar.gz", "fifi_1.2.0_macOS_arm64.tar.gz"),
+			goos:    "darwin",
+			goarch:  "arm64",
+			want:    "fifi_1.2.0_macOS_arm64.tar.gz",
+		},
+		{
+			name:    "darwin aarch64",
+			release: release("fifi_1.2.0_linux_aarch64.tar.gz", "fifi_1.2.0_darwin_aarch64.tar.gz"),
+			goos:    "darwin",
+			goarch:  "arm64",
+			want:    "fifi_1.2.0_darwin_aarch64.tar.gz",
+		},
+		{
+			name:    "macOS x86_64",
+			release: release("fifi_1.2.0_macOS_arm64.tar.gz", "fifi_1.2.0_macOS_x86_64.tar.gz"),
+			goos:    "darwin",
+			goarch:  "amd64",
+			want:    "fifi_1.2.0_macOS_x86_64.tar.gz",
+		},
+		{
+			name:    "darwin amd64",
+			release: release("fifi_1.2.0_darwin_arm64.tar.gz", "fifi_1.2.0_darwin_amd64.tar.gz"),
+			goos:    "darwin",
+			goarch:  "amd64",
+			want:    "fifi_1.2.0_darwin_amd64.tar.gz",
+		},
+		{
+			name:    "linux amd64",
+			release: release("fifi_1.2.0_linux_arm64.tar.gz", "fifi_1.2.0_linux_amd64.tar.gz"),
+			goos:    "linux",
+			goarch:  "amd64",
+			want:    "fifi_1.2.0_linux_amd64.tar.gz",
+		},
+		{
+			name:    "linux aarch64",
+			release: release("fifi_1.2.0_linux_x86_64.tar.gz", "fifi_1.2.0_linux_aarch64.tar.gz"),
+			goos:    "linux",
+			goarch:  "arm64",
+			want:    "fifi_1.2.0_linux_aarch64.tar.gz",
+		},
+		{
+			name:    "windows zip",
+			release: release("fifi_1.2.0_linux_amd64.tar.gz", "fifi_1.2.0_windows_amd64.zip"),
+			goos:    "windows",
+			goarch:  "amd64",
+			want:    "fifi_1.2.0_windows_amd64.zip",
+		},
+		{
+			name:    "version with v prefix",
+			release: release("fifi_v1.2.0_linux_arm64.tar.gz"),
+			goos:    "linux",
+			goarch:  "arm64",
+			want:    "fifi_v1.2.0_linux_arm64.tar.gz",
+		},
+		{
+			name:    "fields in another order",
+			release: release("fifi_linux_1.2.0_arm64.tar.gz"),
+			goos:    "linux",
+			goarch:  "arm64",
+			want:    "fifi_linux_1.2.0_arm64.tar.gz",
+		},
+		{
+			name:    "asset of another version",
+			release: release("fifi_1.1.0_linux_amd64.tar.gz"),
+			goos:    "linux",
+			goarch:  "amd64",
+		},
+		{
+			name:    "arm doesn't match arm64",
+			release: release("fifi_1.2.0_linux_arm64.tar.gz"),
+			goos:    "linux",
+			goarch:  "arm",
+		},
+		{
+			name:    "arm",
+			release: release("fifi_1.2.0_linux_arm64.tar.gz", "fifi_1.2.0_linux_arm.tar.gz"),
+			goos:    "linux",
+			goarch:  "arm",
+			want:    "fifi_1.2.0_linux_arm.tar.gz",
+		},
+		{
+			name:    "legacy name without version",
+			release: release("fifi_Darwin_arm64", "fifi_Linux_x86_64"),
+			goos:    "linux",
+			goarch:  "amd64",
+			want:    "fifi_Linux_x86_64",
+		},
+		{
+			name:    "legacy macOS name",
+			release: release("fifi_Linux_aarch64.tar.gz", "fifi_MacOS_aarch64.tar.gz"),
+			goos:    "darwin",
+			goarch:  "arm64",
+			want:    "fifi_MacOS_aarch64.tar.gz",
+		},
+		{
+			name:    "no asset for the architecture",
+			release: release("fifi_1.2.0_linux_amd64.tar.gz", "fifi_1.2.0_macOS_arm64.tar.gz"),
+			goos:    "linux",
+			goarch:  "arm64",
+		},
+		{
+			name:    "no asset for the OS",
+			release: release("fifi_1.2.0_linux_amd64.tar.gz", "fifi_1.2.0_macOS_amd64.tar.gz"),
+			goos:    "windows",
+			goarch:  "amd64",
+		},
+		{
+			name:    "unknown architecture",
+			release: release("fifi_1.2.0_linux_amd64.tar.gz", "fifi_1.2.0_linux_arm64.tar.gz"),
+			goos:    "linux",
+			goarch:  "386",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			asset, err := findAsset(tt.release, "1.2.0", tt.goos, tt.goarch)
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("findAsset() = %s, want no match", asset.Name)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("findAsset() error = %v", err)
+			}
+			if asset.Name != tt.want {
+				t.Errorf("findAsset() = %s, want %s", asset.Name, tt.want)
+			}
+		})
+	}
+}
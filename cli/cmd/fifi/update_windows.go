@@ -0,0 +1,51 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// oldExecutablePath returns where the running binary is parked during an
+// update, e.g. C:\tools\fifi.old.exe
+func oldExecutablePath(exePath string) string {
+	dir, name := filepath.Split(exePath)
+	return filepath.Join(dir, strings.TrimSuffix(name, filepath.Ext(name))+".old.exe")
+}
+
+// replaceExecutable moves the new binary over the current executable.
+// Windows locks a running executable against writes but still allows it to
+// be renamed, so the current binary is moved aside first and deleted by
+// cleanupOldExecutable on the next run.
+func replaceExecutable(binaryPath, exePath string) error {
+	oldPath := oldExecutablePath(exePath)
+
+	// A leftover from a previous update would block the rename
+	os.Remove(oldPath)
+
+	if err := os.Rename(exePath, oldPath); err != nil {
+		return fmt.Errorf("failed to move current executable aside: %w", err)
+	}
+
+	if err := os.Rename(binaryPath, exePath); err != nil {
+		if err := copyFile(binaryPath, exePath); err != nil {
+			// Put the original binary back so fifi keeps working
+			os.Rename(oldPath, exePath)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// cleanupOldExecutable removes the binary left behind by a previous update
+func cleanupOldExecutable() {
+	exePath, err := os.Executable()
+	if err != nil {
+		return
+	}
+	os.Remove(oldExecutablePath(exePath))
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// updateCheckTTL is how long a cached update check is reused
+const updateCheckTTL = 24 * time.Hour
+
+// updateCheckCache is the on-disk record of the last update check
+type updateCheckCache struct {
+	CheckedAt     time.Time `json:"checked_at"`
+	LatestVersion string    `json:"latest_version"`
+}
+
+// updateCheckCachePath returns <user cache dir>/fifi/update-check.json
+func updateCheckCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "fifi", "update-check.json"), nil
+}
+
+// cachedLatestVersion returns the latest version recorded by a previous
+// check, if it is younger than updateCheckTTL. Setting
+// FIFI_UPDATE_CHECK_REFRESH=1 ignores the cache.
+func cachedLatestVersion() (string, bool) {
+	if os.Getenv("FIFI_UPDATE_CHECK_REFRESH") == "1" {
+		return "", false
+	}
+
+	path, err := updateCheckCachePath()
+	if err != nil {
+		return "", false
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	var cache updateCheckCache
+	if err := json.Unmarshal(content, &cache); err != nil {
+		return "", false
+	}
+
+	if cache.LatestVersion == "" || time.Since(cache.CheckedAt) > updateCheckTTL {
+		return "", false
+	}
+	return cache.LatestVersion, true
+}
+
+// saveLatestVersion records the result of an update check. Failures are
+// ignored since the cache is only an optimization.
+func saveLatestVersion(version string) {
+	path, err := updateCheckCachePath()
+	if err != nil {
+		return
+	}
+
+	content, err := json.Marshal(updateCheckCache{
+		CheckedAt:     time.Now(),
+		LatestVersion: version,
+	})
+	if err != nil {
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	os.WriteFile(path, content, 0644)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+
+	initpkg "github.com/dscv103/fionacode/cli/internal/init"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeOverwrite   bool
+	upgradeMergeConfig bool
+	upgradeNoBackup    bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [directory]",
+	Short: "Refresh a project's prompts and tools from the embedded configuration",
+	Long: `Update the prompt and tool files of an initialized project to match the
+versions embedded in this fifi binary.
+
+Files the project is missing are added. Files that differ from the embedded
+version are treated as local customizations and kept unless --overwrite is
+set; overwritten files are backed up first unless --no-backup is set.
+
+opencode.json is left alone unless --merge-config is set, which adds missing
+agents, tools and MCP servers without touching existing entries.
+
+Run 'fifi diff' first to see what would change.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeDirectory,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		var targetDir string
+		if len(args) > 0 {
+			targetDir = args[0]
+		}
+
+		result, err := initpkg.Upgrade(targetDir, initpkg.UpgradeOptions{
+			Overwrite:   upgradeOverwrite,
+			MergeConfig: upgradeMergeConfig,
+			NoBackup:    upgradeNoBackup,
+		})
+		if err != nil {
+			return fmt.Errorf("upgrade failed: %w", err)
+		}
+
+		for _, path := range result.Added {
+			fmt.Printf("  added    %s\n", path)
+		}
+		for _, path := range result.Updated {
+			fmt.Printf("  updated  %s\n", path)
+		}
+		for _, path := range result.Skipped {
+			fmt.Printf("  skipped  %s (modified; use --overwrite to replace)\n", path)
+		}
+		if result.ConfigMerged {
+			fmt.Println("  merged   opencode.json")
+			for _, conflict := range result.Conflicts {
+				fmt.Printf("           kept existing %s\n", conflict)
+			}
+		}
+
+		if len(result.Added)+len(result.Updated) == 0 && !result.ConfigMerged {
+			fmt.Printf("%s Project is already up to date\n", okMark())
+		} else {
+			fmt.Printf("\n%s Upgraded: %d added, %d updated, %d skipped\n", okMark(),
+				len(result.Added), len(result.Updated), len(result.Skipped))
+		}
+		if result.BackupDir != "" {
+			fmt.Printf("  Backup of overwritten files: %s\n", result.BackupDir)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeOverwrite, "overwrite", false, "Replace prompt and tool files that differ from the embedded version")
+	upgradeCmd.Flags().BoolVar(&upgradeMergeConfig, "merge-config", false, "Add missing embedded entries to opencode.json")
+	upgradeCmd.Flags().BoolVar(&upgradeNoBackup, "no-backup", false, "Don't back up files replaced by --overwrite")
+	rootCmd.AddCommand(upgradeCmd)
+}
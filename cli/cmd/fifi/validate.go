@@ -1,57 +1,462 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
+	initpkg "github.com/dscv103/fionacode/cli/internal/init"
 	"github.com/dscv103/fionacode/cli/internal/validate"
 	"github.com/spf13/cobra"
 )
 
 var (
-	showSummary bool
+	showSummary      bool
+	ignoreCategories []string
+	validateJSON     bool
+	strictValidate   bool
+	schemaOnly       bool
+	summaryVerbose   bool
+	recursive        bool
+	useSchemaRef     bool
+	fixValidate      bool
+	configFile       string
 )
 
+// validateReport is the --json output of fifi validate
+type validateReport struct {
+	// Directory is set when several directories are validated
+	Directory string `json:"directory,omitempty"`
+	// Error is set when the directory couldn't be validated at all
+	Error   string           `json:"error,omitempty"`
+	Valid   bool             `json:"valid"`
+	Issues  []validate.Issue `json:"issues"`
+	Summary issueCounts      `json:"summary"`
+	// Config summarizes the configuration when --summary is set
+	Config *validate.Summary `json:"config,omitempty"`
+}
+
+// issueCounts is how many errors and warnings validation found
+type issueCounts struct {
+	Errors   int `json:"errors"`
+	Warnings int `json:"warnings"`
+}
+
 var validateCmd = &cobra.Command{
-	Use:   "validate [directory]",
+	Use:   "validate [directory...]",
 	Short: "Validate an existing FionaCode configuration",
 	Long: `Validate an existing FionaCode configuration by checking opencode.json and .opencode directory.
 
-If no directory is specified, validates the current directory.`,
-	Args: cobra.MaximumNArgs(1),
+If no directory is specified, validates the current directory (or the one
+given with -C). A single positional directory is deprecated in favor of -C.
+With several directories, each is validated in turn and the command fails if
+any of them does.
+
+With --recursive, every project (directory containing an opencode.json) below
+the given directories is validated. Hidden directories, node_modules and
+vendor are skipped.
+
+opencode.json is checked against an embedded JSON Schema before the
+filesystem checks run; --schema-only runs just the schema check. With
+--use-schema, opencode.json is also validated against the schema its $schema
+field references (a URL or a path relative to the project). If that schema
+can't be loaded, a warning is printed and the built-in checks still apply.
+
+Warnings are printed but don't fail validation unless --strict is set.
+
+With --config, the given file is validated instead of the opencode.json of a
+project directory, e.g. "fifi validate --config config/opencode.json". The
+.opencode directory and prompt paths are resolved relative to the directory
+containing that file. No directory arguments are accepted with --config.
+
+With --fix, problems that are unambiguous and safe to undo are repaired
+before validating: a missing .opencode, .opencode/prompts or .opencode/tool
+directory is created, and prompt files agents reference but that are missing
+are restored from the embedded configuration. Existing files are never
+changed and every change is listed; everything else is reported as usual.
+
+Findings of a given category can be suppressed with --ignore (repeatable).
+Valid categories: ` + categoryNames() + `.
+
+Exit codes:
+  0  the configuration is valid
+  2  opencode.json was not found
+  3  opencode.json could not be parsed
+  4  validation reported errors (or warnings with --strict)
+When several projects fail for different reasons, the highest code is used.`,
+	Args: cobra.ArbitraryArgs,
+	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		var targetDir string
-		if len(args) > 0 {
-			targetDir = args[0]
+		if configFile != "" && len(args) > 0 {
+			return fmt.Errorf("directory arguments can't be combined with --config")
 		}
 
-		fmt.Printf("Validating FionaCode configuration")
-		if targetDir != "" {
-			fmt.Printf(" in %s", targetDir)
-		} else {
-			fmt.Printf(" in current directory")
+		// An empty directory means the current one
+		dirs := args
+		if configFile != "" {
+			// Only shown in messages; Validate reads opts.ConfigFile
+			dirs = []string{configFile}
+		} else if len(dirs) == 0 {
+			dirs = []string{""}
+		} else if len(dirs) == 1 && !recursive {
+			warnDeprecatedDirArg(cmd, dirs[0])
+		}
+
+		if summaryVerbose {
+			showSummary = true
 		}
-		fmt.Println("...")
 
-		if err := validate.Validate(targetDir); err != nil {
-			return fmt.Errorf("validation failed: %w", err)
+		opts := validate.Options{SchemaOnly: schemaOnly, ReferencedSchema: useSchemaRef, ConfigFile: configFile}
+
+		if recursive {
+			var err error
+			dirs, err = findProjects(dirs)
+			if err != nil {
+				return withExitCode(cmd, validateExitCode(err), err)
+			}
 		}
 
-		fmt.Println("\n✓ Configuration is valid!")
+		for _, name := range ignoreCategories {
+			category, err := validate.ParseCategory(name)
+			if err != nil {
+				return fmt.Errorf("invalid --ignore value: %w", err)
+			}
+			opts.Ignore = append(opts.Ignore, category)
+		}
+
+		if validateJSON {
+			return runValidateJSON(cmd, dirs, opts)
+		}
 
-		if showSummary {
-			fmt.Println()
-			summary, err := validate.GetSummary(targetDir)
+		if len(dirs) == 1 && !recursive {
+			issues, err := validateDir(dirs[0], opts)
 			if err != nil {
-				return fmt.Errorf("failed to get summary: %w", err)
+				return withExitCode(cmd, validateExitCode(err), fmt.Errorf("validation failed: %w", err))
+			}
+			if validationFailed(issues) {
+				return withExitCode(cmd, exitValidationFailed, fmt.Errorf("validation failed: %s", countIssues(issues)))
+			}
+			return nil
+		}
+
+		failed, code := 0, 0
+		for i, dir := range dirs {
+			if i > 0 {
+				fmt.Println()
+			}
+			issues, err := validateDir(dir, opts)
+			switch {
+			case err != nil:
+				fmt.Printf("  %s validation failed: %v\n", errorMark(), err)
+				failed++
+				code = max(code, validateExitCode(err))
+			case validationFailed(issues):
+				fmt.Printf("  %s validation failed: %s\n", errorMark(), countIssues(issues))
+				failed++
+				code = max(code, exitValidationFailed)
 			}
-			fmt.Println(summary)
 		}
 
+		fmt.Printf("\n%d of %d projects passed validation\n", len(dirs)-failed, len(dirs))
+		if failed > 0 {
+			return withExitCode(cmd, code, fmt.Errorf("validation failed in %d of %d projects", failed, len(dirs)))
+		}
 		return nil
 	},
 }
 
+// Exit codes of the validate command
+const (
+	exitConfigNotFound   = 2
+	exitConfigParse      = 3
+	exitValidationFailed = 4
+)
+
+// validateExitCode maps an error from validate.Validate to an exit code
+func validateExitCode(err error) int {
+	var parseErr *validate.ParseError
+	switch {
+	case errors.Is(err, validate.ErrConfigNotFound):
+		return exitConfigNotFound
+	case errors.As(err, &parseErr):
+		return exitConfigParse
+	default:
+		return 1
+	}
+}
+
+// findProjects returns the projects below each root, which defaults to the
+// current directory
+func findProjects(roots []string) ([]string, error) {
+	var projects []string
+	for _, root := range roots {
+		if root == "" {
+			root = "."
+		}
+		found, err := validate.FindProjects(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search %s: %w", root, err)
+		}
+		if len(found) == 0 {
+			return nil, fmt.Errorf("%w under %s", validate.ErrConfigNotFound, root)
+		}
+		projects = append(projects, found...)
+	}
+	return projects, nil
+}
+
+// repairDir applies validate --fix to one directory and lists what changed.
+// A missing or unparsable opencode.json is left for validation to report.
+func repairDir(targetDir string) error {
+	dir := targetDir
+	if dir == "" {
+		dir = "."
+	}
+	result, err := initpkg.Repair(dir, nil)
+	var parseErr *validate.ParseError
+	if errors.Is(err, validate.ErrConfigNotFound) || errors.As(err, &parseErr) {
+		return nil
+	}
+	if result != nil && result.Changed() {
+		fmt.Println("\nFixed:")
+		for _, created := range result.Created {
+			fmt.Printf("  %s created %s/\n", okMark(), created)
+		}
+		for _, restored := range result.Restored {
+			fmt.Printf("  %s restored %s\n", okMark(), restored)
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("failed to fix: %w", err)
+	}
+	return nil
+}
+
+// validateDir validates one directory, printing its issues, and its
+// summary when requested and the configuration is valid. The error is
+// non-nil only if the configuration couldn't be checked at all.
+func validateDir(targetDir string, opts validate.Options) ([]validate.Issue, error) {
+	fmt.Printf("Validating FionaCode configuration in %s...\n", displayTarget(targetDir))
+
+	if fixValidate {
+		if err := repairDir(targetDir); err != nil {
+			return nil, err
+		}
+	}
+
+	issues, err := validate.Validate(targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	printIssues(issues)
+	if validationFailed(issues) {
+		return issues, nil
+	}
+
+	fmt.Printf("\n%s Configuration is valid!\n", okMark())
+
+	if showSummary {
+		fmt.Println()
+		summary, err := summarize(targetDir, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summary: %w", err)
+		}
+		fmt.Println(formatSummary(summary))
+	}
+
+	return issues, nil
+}
+
+// summarize summarizes the config validated with opts in targetDir
+func summarize(targetDir string, opts validate.Options) (*validate.Summary, error) {
+	if opts.ConfigFile != "" {
+		return validate.SummarizeFile(opts.ConfigFile)
+	}
+	return validate.Summarize(targetDir)
+}
+
+// runValidateJSON validates each directory and writes only JSON to stdout:
+// a single validateReport, or an array of them when several directories
+// were given
+func runValidateJSON(cmd *cobra.Command, dirs []string, opts validate.Options) error {
+	if len(dirs) == 1 && !recursive {
+		report, err := buildValidateReport(dirs[0], opts)
+		if err != nil {
+			return withExitCode(cmd, validateExitCode(err), fmt.Errorf("validation failed: %w", err))
+		}
+		if err := writeJSON(report); err != nil {
+			return err
+		}
+		if !report.Valid {
+			return withExitCode(cmd, exitValidationFailed, nil)
+		}
+		return nil
+	}
+
+	reports := make([]*validateReport, 0, len(dirs))
+	code := 0
+	for _, dir := range dirs {
+		report, err := buildValidateReport(dir, opts)
+		if err != nil {
+			report = &validateReport{Issues: []validate.Issue{}, Error: err.Error()}
+			code = max(code, validateExitCode(err))
+		} else if !report.Valid {
+			code = max(code, exitValidationFailed)
+		}
+		report.Directory = dir
+		reports = append(reports, report)
+	}
+
+	if err := writeJSON(reports); err != nil {
+		return err
+	}
+	if code != 0 {
+		return withExitCode(cmd, code, nil)
+	}
+	return nil
+}
+
+// buildValidateReport validates targetDir into a validateReport
+func buildValidateReport(targetDir string, opts validate.Options) (*validateReport, error) {
+	issues, err := validate.Validate(targetDir, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &validateReport{
+		Valid:   !validationFailed(issues),
+		Issues:  issues,
+		Summary: countSeverities(issues),
+	}
+	if report.Issues == nil {
+		report.Issues = []validate.Issue{}
+	}
+	if showSummary && report.Valid {
+		report.Config, err = summarize(targetDir, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get summary: %w", err)
+		}
+	}
+	return report, nil
+}
+
+// writeJSON writes v to stdout as indented JSON
+func writeJSON(v interface{}) error {
+	return writeJSONTo(os.Stdout, v)
+}
+
+// writeJSONTo writes v to w as indented JSON
+func writeJSONTo(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to write JSON: %w", err)
+	}
+	return nil
+}
+
+// formatSummary renders a configuration summary for the terminal
+func formatSummary(summary *validate.Summary) string {
+	var b strings.Builder
+	b.WriteString("Configuration Summary:\n")
+	fmt.Fprintf(&b, "  Agent: %d\n", summary.Agents)
+	fmt.Fprintf(&b, "  MCP Servers: %d\n", summary.MCPServers)
+	fmt.Fprintf(&b, "  Tools (enabled/disabled): %d/%d\n", summary.EnabledTools, summary.DisabledTools)
+
+	if summaryVerbose && len(summary.AgentDetails) > 0 {
+		b.WriteString("\nAgents:\n")
+		for _, agent := range summary.AgentDetails {
+			agentType := agent.Type
+			if agentType == "" {
+				agentType = "-"
+			}
+			prompt := "no prompt"
+			switch {
+			case agent.HasPrompt:
+				prompt = agent.Prompt
+			case agent.Prompt != "":
+				prompt = agent.Prompt + " (missing)"
+			}
+			fmt.Fprintf(&b, "  %-20s %-10s temperature %-4g %s\n", agent.Name, agentType, agent.Temperature, prompt)
+		}
+	}
+	return b.String()
+}
+
+// validationFailed reports whether issues should fail the command: any
+// error, or with --strict any issue at all
+func validationFailed(issues []validate.Issue) bool {
+	if strictValidate {
+		return len(issues) > 0
+	}
+	return validate.HasErrors(issues)
+}
+
+// printIssues prints each validation issue on its own line
+func printIssues(issues []validate.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	fmt.Println()
+	for _, issue := range issues {
+		marker := errorMark()
+		if issue.Severity == validate.SeverityWarning {
+			marker = warningMark()
+		}
+		fmt.Printf("  %s %s\n", marker, issue)
+	}
+}
+
+// countIssues describes how many errors and warnings were found
+func countIssues(issues []validate.Issue) string {
+	counts := countSeverities(issues)
+	return fmt.Sprintf("%d error(s), %d warning(s)", counts.Errors, counts.Warnings)
+}
+
+// countSeverities counts the errors and warnings among issues
+func countSeverities(issues []validate.Issue) issueCounts {
+	var counts issueCounts
+	for _, issue := range issues {
+		if issue.Severity == validate.SeverityError {
+			counts.Errors++
+		} else {
+			counts.Warnings++
+		}
+	}
+	return counts
+}
+
+// categoryNames returns the valid --ignore values as a comma-separated list
+func categoryNames() string {
+	var names []string
+	for _, c := range validate.Categories() {
+		names = append(names, string(c))
+	}
+	return strings.Join(names, ", ")
+}
+
 func init() {
 	validateCmd.Flags().BoolVarP(&showSummary, "summary", "s", false, "Show configuration summary")
+	validateCmd.Flags().StringArrayVar(&ignoreCategories, "ignore", nil, "Suppress findings of this category (repeatable): "+categoryNames())
+	validateCmd.Flags().BoolVar(&summaryVerbose, "summary-verbose", false, "Show configuration summary including each agent")
+	validateCmd.Flags().BoolVar(&useSchemaRef, "use-schema", false, "Also validate against the schema referenced by $schema")
+	validateCmd.Flags().BoolVar(&schemaOnly, "schema-only", false, "Only check opencode.json against its JSON Schema")
+	validateCmd.Flags().BoolVar(&strictValidate, "strict", false, "Treat warnings as errors")
+	validateCmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Validate every project found below the given directories")
+	validateCmd.Flags().BoolVar(&validateJSON, "json", false, "Write the results as JSON to stdout")
+	validateCmd.Flags().BoolVar(&fixValidate, "fix", false, "Recreate missing directories and restore missing prompt files before validating")
+	validateCmd.Flags().StringVar(&configFile, "config", "", "Validate this config file instead of the project's opencode.json")
+	validateCmd.MarkFlagsMutuallyExclusive("fix", "json")
+	validateCmd.MarkFlagsMutuallyExclusive("config", "recursive")
+	validateCmd.MarkFlagsMutuallyExclusive("config", "fix")
+	validateCmd.MarkFlagFilename("config", "json", "jsonc")
+	validateCmd.RegisterFlagCompletionFunc("ignore", completeCategories)
 	rootCmd.AddCommand(validateCmd)
 }
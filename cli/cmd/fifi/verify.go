@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	initpkg "github.com/dscv103/fionacode/cli/internal/init"
+	"github.com/spf13/cobra"
+)
+
+var verifyAll bool
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Detect files changed since init",
+	Long: `Recompute the SHA256 of the files recorded in .opencode/manifest.json and
+report every file that no longer matches. The manifest is written by
+"fifi init --manifest".
+
+Each reported file is:
+  modified   the file differs from what init wrote
+  missing    init wrote the file but it no longer exists
+  untracked  a prompt or tool file the manifest doesn't list
+
+With --all, unchanged files are listed as identical too.
+
+verify exits with status 1 when any file is reported. Use -C to verify a
+project outside the current directory.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		manifest, diffs, err := initpkg.Verify(".")
+		if err != nil {
+			return fmt.Errorf("failed to verify project: %w", err)
+		}
+
+		counts := make(map[initpkg.FileStatus]int)
+		for _, diff := range diffs {
+			counts[diff.Status]++
+			if diff.Status != initpkg.StatusIdentical || verifyAll {
+				fmt.Printf("  %-10s %s\n", diff.Status, diff.Path)
+			}
+		}
+
+		drift := len(diffs) - counts[initpkg.StatusIdentical]
+		if drift == 0 {
+			fmt.Printf("%s All %d files match the manifest written by fifi %s\n",
+				okMark(), counts[initpkg.StatusIdentical], manifest.Version)
+			return nil
+		}
+
+		fmt.Printf("\n%d identical, %d modified, %d missing, %d untracked\n",
+			counts[initpkg.StatusIdentical], counts[initpkg.StatusModified],
+			counts[initpkg.StatusMissing], counts[initpkg.StatusUntracked])
+		return withExitCode(cmd, 1, nil)
+	},
+}
+
+func init() {
+	verifyCmd.Flags().BoolVar(&verifyAll, "all", false, "Also list files that match the manifest")
+	rootCmd.AddCommand(verifyCmd)
+}
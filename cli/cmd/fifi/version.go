@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/dscv103/fionacode/cli/internal/assets"
+	"github.com/spf13/cobra"
+)
+
+var (
+	versionJSON bool
+)
+
+// buildInfo describes the running fifi binary
+type buildInfo struct {
+	Version   string `json:"version"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	// Framework describes the embedded FionaCode configuration
+	Framework assets.Metadata `json:"framework"`
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print version and build information",
+	Long: `Print the fifi version, build date, Go version and platform, and the
+version of the FionaCode framework configuration embedded in the binary.
+
+Use --json for machine-readable output, e.g. in bug reports or CI checks.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		framework, err := assets.Manifest()
+		if err != nil {
+			return fmt.Errorf("failed to read embedded assets: %w", err)
+		}
+
+		info := buildInfo{
+			Version:   Version,
+			BuildDate: BuildDate,
+			GoVersion: runtime.Version(),
+			OS:        runtime.GOOS,
+			Arch:      runtime.GOARCH,
+			Framework: framework,
+		}
+
+		if versionJSON {
+			return writeJSON(info)
+		}
+
+		fmt.Printf("fifi version %s (built %s)\n", info.Version, info.BuildDate)
+		fmt.Printf("  go:        %s\n", info.GoVersion)
+		fmt.Printf("  platform:  %s/%s\n", info.OS, info.Arch)
+		fmt.Printf("  framework: %s (%d agents, %d prompts, %d tools, digest %.12s)\n",
+			framework.FrameworkVersion, framework.Agents, framework.Prompts, framework.Tools, framework.Digest)
+		return nil
+	},
+}
+
+func init() {
+	versionCmd.Flags().BoolVar(&versionJSON, "json", false, "Print build information as JSON")
+	rootCmd.AddCommand(versionCmd)
+}
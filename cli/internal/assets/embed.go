@@ -2,6 +2,7 @@ package assets
 
 import (
 	"embed"
+	"io/fs"
 )
 
 // Embed the entire embedded directory including dotfiles
@@ -9,39 +10,55 @@ import (
 //go:embed embedded/opencode.json embedded/.opencode/prompts/* embedded/.opencode/tool/*
 var Assets embed.FS
 
+// fileModes records the permissions of embedded files that need more than
+// the default 0644, since embed.FS doesn't preserve file modes. Keys are
+// asset paths.
+var fileModes = map[string]fs.FileMode{
+	"embedded/.opencode/tool/exit_criteria_checker.py": 0755,
+	"embedded/.opencode/tool/extract_api.py":           0755,
+	"embedded/.opencode/tool/validate_docstrings.py":   0755,
+}
+
+// FileMode returns the permissions an embedded file should be written with:
+// 0755 for executable scripts and 0644 for everything else
+func FileMode(path string) fs.FileMode {
+	if mode, ok := fileModes[path]; ok {
+		return mode
+	}
+	return 0644
+}
+
 // GetOpencodeJSON returns the opencode.json content
 func GetOpencodeJSON() ([]byte, error) {
 	return Assets.ReadFile("embedded/opencode.json")
 }
 
-// GetPromptFiles returns all prompt file paths
+// GetPromptFiles returns all prompt file paths, including files in
+// subdirectories
 func GetPromptFiles() ([]string, error) {
-	entries, err := Assets.ReadDir("embedded/.opencode/prompts")
-	if err != nil {
-		return nil, err
-	}
-
-	var files []string
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			files = append(files, "embedded/.opencode/prompts/"+entry.Name())
-		}
-	}
-	return files, nil
+	return walkFiles("embedded/.opencode/prompts")
 }
 
-// GetToolFiles returns all tool file paths
+// GetToolFiles returns all tool file paths, including files in
+// subdirectories
 func GetToolFiles() ([]string, error) {
-	entries, err := Assets.ReadDir("embedded/.opencode/tool")
-	if err != nil {
-		return nil, err
-	}
+	return walkFiles("embedded/.opencode/tool")
+}
 
+// walkFiles returns the paths of every file below root in lexical order
+func walkFiles(root string) ([]string, error) {
 	var files []string
-	for _, entry := range entries {
-		if !entry.IsDir() {
-			files = append(files, "embedded/.opencode/tool/"+entry.Name())
+	err := fs.WalkDir(Assets, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
 		}
+		if !d.IsDir() {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return files, nil
 }
@@ -50,3 +67,8 @@ func GetToolFiles() ([]string, error) {
 func ReadFile(path string) ([]byte, error) {
 	return Assets.ReadFile(path)
 }
+
+// Open opens a file from the embedded assets for streaming
+func Open(path string) (fs.File, error) {
+	return Assets.Open(path)
+}
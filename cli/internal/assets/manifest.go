@@ -0,0 +1,82 @@
+package assets
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+)
+
+// FrameworkVersion is the FionaCode framework version the embedded assets
+// come from. Release builds set it with
+// -ldflags "-X github.com/dscv103/fionacode/cli/internal/assets.FrameworkVersion=v1.2.3".
+var FrameworkVersion = "dev"
+
+// Metadata describes the embedded FionaCode configuration
+type Metadata struct {
+	// FrameworkVersion is the framework release the assets come from
+	FrameworkVersion string `json:"frameworkVersion"`
+	// Digest is the SHA256 over every embedded file's path and content, so
+	// two binaries ship the same configuration exactly if it matches
+	Digest  string `json:"digest"`
+	Agents  int    `json:"agents"`
+	Prompts int    `json:"prompts"`
+	Tools   int    `json:"tools"`
+}
+
+var (
+	manifestOnce sync.Once
+	manifest     Metadata
+	manifestErr  error
+)
+
+// Manifest returns the version, digest and file counts of the embedded
+// assets. It is computed once and cached.
+func Manifest() (Metadata, error) {
+	manifestOnce.Do(func() {
+		manifest, manifestErr = computeManifest()
+	})
+	return manifest, manifestErr
+}
+
+// computeManifest hashes and counts the embedded files
+func computeManifest() (Metadata, error) {
+	meta := Metadata{FrameworkVersion: FrameworkVersion}
+
+	config, err := GetOpencodeJSON()
+	if err != nil {
+		return meta, err
+	}
+	var parsed struct {
+		Agent map[string]json.RawMessage `json:"agent"`
+	}
+	if err := json.Unmarshal(config, &parsed); err != nil {
+		return meta, err
+	}
+	meta.Agents = len(parsed.Agent)
+
+	prompts, err := GetPromptFiles()
+	if err != nil {
+		return meta, err
+	}
+	tools, err := GetToolFiles()
+	if err != nil {
+		return meta, err
+	}
+	meta.Prompts, meta.Tools = len(prompts), len(tools)
+
+	// Both lists are in lexical order, so the digest is stable
+	hash := sha256.New()
+	for _, path := range append(append([]string{"embedded/opencode.json"}, prompts...), tools...) {
+		content, err := ReadFile(path)
+		if err != nil {
+			return meta, err
+		}
+		hash.Write([]byte(path))
+		hash.Write([]byte{0})
+		hash.Write(content)
+		hash.Write([]byte{0})
+	}
+	meta.Digest = hex.EncodeToString(hash.Sum(nil))
+	return meta, nil
+}
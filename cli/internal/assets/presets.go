@@ -0,0 +1,65 @@
+package assets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultPreset is the preset init uses unless another one is chosen
+const DefaultPreset = "full"
+
+// Preset is a bundled profile selecting part of the embedded configuration.
+// Each list names what the preset includes: agents by name, prompt and tool
+// files by file name, and MCP servers by name. A nil list includes
+// everything; an empty list includes nothing.
+type Preset struct {
+	Name        string
+	Description string
+	Agents      []string
+	Prompts     []string
+	Tools       []string
+	MCP         []string
+}
+
+var presets = []Preset{
+	{
+		Name:        "full",
+		Description: "All agents, prompts, tools and MCP servers",
+	},
+	{
+		Name:        "minimal",
+		Description: "The orchestrator with planning, implementation and navigation agents, no MCP servers",
+		Agents:      []string{"orchestrator", "planning", "implementer", "file-navigator"},
+		Prompts:     []string{"orchestrator.txt", "planning.txt", "implementer.txt", "file-navigator.txt"},
+		Tools: []string{
+			"agent_handoff_validator.ts",
+			"branch_strategy_enforcer.ts",
+			"coverage_analyzer.ts",
+			"exit_criteria_checker.js",
+			"exit_criteria_checker.py",
+			"fixture_generator.ts",
+			"task_tracker.ts",
+			"test_runner_smart.ts",
+			"type_check_aggregator.ts",
+			"utils.ts",
+		},
+		MCP: []string{},
+	},
+}
+
+// Presets returns every bundled preset
+func Presets() []Preset {
+	return presets
+}
+
+// LookupPreset returns the bundled preset called name
+func LookupPreset(name string) (Preset, error) {
+	var names []string
+	for _, preset := range presets {
+		if preset.Name == name {
+			return preset, nil
+		}
+		names = append(names, preset.Name)
+	}
+	return Preset{}, fmt.Errorf("unknown preset %q (valid presets: %s)", name, strings.Join(names, ", "))
+}
@@ -0,0 +1,99 @@
+package init
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/jsonedit"
+)
+
+// agentSource limits a Source to a chosen set of agents and the prompt
+// files they reference. Tool files and MCP servers are left alone.
+type agentSource struct {
+	Source
+	agents []string
+	// prompts holds the slash-separated prompt paths the agents reference
+	prompts map[string]bool
+}
+
+// withAgents returns src limited to the named agents, failing with the
+// available names if any of them isn't defined in its opencode.json
+func withAgents(src Source, names []string) (Source, error) {
+	content, err := src.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Agent map[string]struct {
+			Prompt string `json:"prompt"`
+		} `json:"agent"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse opencode.json: %w", err)
+	}
+
+	prompts := make(map[string]bool)
+	var unknown []string
+	for _, name := range names {
+		agent, ok := config.Agent[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if agent.Prompt != "" {
+			prompts[path.Clean(filepath.ToSlash(agent.Prompt))] = true
+		}
+	}
+	if len(unknown) > 0 {
+		available := make([]string, 0, len(config.Agent))
+		for name := range config.Agent {
+			available = append(available, name)
+		}
+		sort.Strings(available)
+		return nil, fmt.Errorf("unknown agent %s (available: %s)", strings.Join(unknown, ", "), strings.Join(available, ", "))
+	}
+
+	return agentSource{Source: src, agents: names, prompts: prompts}, nil
+}
+
+// ReadConfig returns the source's opencode.json without the agents that
+// weren't chosen
+func (s agentSource) ReadConfig() ([]byte, error) {
+	content, err := s.Source.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Agent map[string]json.RawMessage `json:"agent"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse opencode.json: %w", err)
+	}
+	for _, name := range excluded(config.Agent, s.agents) {
+		content, err = jsonedit.Remove(content, "agent", name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+// PromptFiles returns only the prompt files the chosen agents reference
+func (s agentSource) PromptFiles() ([]string, error) {
+	files, err := s.Source.PromptFiles()
+	if err != nil {
+		return nil, err
+	}
+	var filtered []string
+	for _, file := range files {
+		if s.prompts[file] {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered, nil
+}
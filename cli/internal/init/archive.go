@@ -0,0 +1,235 @@
+package init
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+)
+
+// Archive writes what Initialize would create to w as a gzipped tarball
+// instead of a directory. Entry names are relative to the project root.
+//
+// Merge, Force and NoBackup have no effect since there is no existing
+// project to merge into or overwrite.
+func Archive(w io.Writer, opts Options) (*Result, error) {
+	src, err := resolveSource(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(w)
+	a := &archiveWriter{tw: tar.NewWriter(gz), modTime: time.Now()}
+	result := &Result{}
+
+	var config []byte
+	if opts.includes(ComponentConfig) || opts.EnvExample {
+		if config, err = src.ReadConfig(); err != nil {
+			return nil, fmt.Errorf("failed to read opencode.json: %w", err)
+		}
+	}
+
+	if opts.includes(ComponentConfig) {
+		content := config
+		if opts.NormalizeJSON {
+			if content, err = normalizeJSON(content); err != nil {
+				return nil, fmt.Errorf("failed to copy opencode.json: %w", err)
+			}
+		}
+		if err := a.writeFile("opencode.json", content, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.includes(ComponentPrompts) {
+		files, err := src.PromptFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy prompt files: %w", err)
+		}
+		if err := a.copyFiles(src, ".opencode/prompts", files); err != nil {
+			return nil, fmt.Errorf("failed to copy prompt files: %w", err)
+		}
+		result.PromptFiles = len(files)
+	}
+
+	if opts.includes(ComponentTool) {
+		files, err := src.ToolFiles()
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy tool files: %w", err)
+		}
+		if err := a.copyFiles(src, ".opencode/tool", files); err != nil {
+			return nil, fmt.Errorf("failed to copy tool files: %w", err)
+		}
+		result.ToolFiles = len(files)
+	}
+
+	if opts.EnvExample {
+		content, names, err := envExampleContent(nil, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write .env.example: %w", err)
+		}
+		if len(names) > 0 {
+			if err := a.writeFile(".env.example", content, 0644); err != nil {
+				return nil, err
+			}
+			result.EnvExampleVars = names
+		}
+	}
+
+	if opts.GitIgnore {
+		if err := a.writeFile(".gitignore", gitignoreContent(nil), 0644); err != nil {
+			return nil, err
+		}
+		result.GitIgnoreUpdated = true
+	}
+
+	if opts.Manifest {
+		content, err := marshalManifest(&Manifest{Version: opts.Version, Files: a.hashes})
+		if err != nil {
+			return nil, err
+		}
+		if err := a.writeFile(ManifestFile, content, 0644); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := a.tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+
+	result.Files = a.files
+	return result, nil
+}
+
+// archiveWriter adds files and their parent directories to a tarball
+type archiveWriter struct {
+	tw      *tar.Writer
+	modTime time.Time
+	// dirs holds the directory entries already written
+	dirs  map[string]bool
+	files []string
+	// hashes holds the SHA256 of the files a manifest tracks
+	hashes map[string]string
+}
+
+// copyFiles streams source files into the archive, adding dir even if
+// files is empty so the project layout is complete
+func (a *archiveWriter) copyFiles(src Source, dir string, files []string) error {
+	if err := a.writeDir(dir); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := a.copyFile(src, file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile streams one source file into the archive, keeping its mode
+func (a *archiveWriter) copyFile(src Source, file string) error {
+	mode, err := src.FileMode(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	in, err := src.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	if err := a.writeHeader(file, info.Size(), mode); err != nil {
+		return err
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(a.tw, hash), in); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", file, err)
+	}
+	a.recordHash(file, hash.Sum(nil))
+	return nil
+}
+
+// recordHash remembers the SHA256 of a file for the manifest
+func (a *archiveWriter) recordHash(name string, sum []byte) {
+	if !manifestTracked(name) {
+		return
+	}
+	if a.hashes == nil {
+		a.hashes = make(map[string]string)
+	}
+	a.hashes[name] = hex.EncodeToString(sum)
+}
+
+// writeFile adds a file with the given content to the archive
+func (a *archiveWriter) writeFile(name string, content []byte, mode os.FileMode) error {
+	if err := a.writeHeader(name, int64(len(content)), mode); err != nil {
+		return err
+	}
+	if _, err := a.tw.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	sum := sha256.Sum256(content)
+	a.recordHash(name, sum[:])
+	return nil
+}
+
+// writeHeader adds the header of a regular file, preceded by entries for
+// any of its parent directories not written yet
+func (a *archiveWriter) writeHeader(name string, size int64, mode os.FileMode) error {
+	if dir := path.Dir(name); dir != "." {
+		if err := a.writeDir(dir); err != nil {
+			return err
+		}
+	}
+	logger.Debug("adding file to archive", "file", name, "size", size, "mode", mode.String())
+	err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Size:     size,
+		Mode:     int64(mode.Perm()),
+		ModTime:  a.modTime,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", name, err)
+	}
+	a.files = append(a.files, name)
+	return nil
+}
+
+// writeDir adds entries for dir and its parents unless already written
+func (a *archiveWriter) writeDir(dir string) error {
+	if a.dirs[dir] {
+		return nil
+	}
+	if parent := path.Dir(dir); parent != "." {
+		if err := a.writeDir(parent); err != nil {
+			return err
+		}
+	}
+	err := a.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir + "/",
+		Mode:     0755,
+		ModTime:  a.modTime,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write %s to archive: %w", dir, err)
+	}
+	if a.dirs == nil {
+		a.dirs = make(map[string]bool)
+	}
+	a.dirs[dir] = true
+	return nil
+}
@@ -0,0 +1,59 @@
+package init
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// backupDirPrefix is the name prefix of the directories created by backupFiles
+const backupDirPrefix = ".opencode.bak-"
+
+// backupFiles copies every file in relPaths that exists in targetDir into a
+// timestamped backup directory (e.g. .opencode.bak-20240101-120000/),
+// preserving relative paths. It returns the backup directory, or "" if none
+// of the files existed.
+func backupFiles(targetDir string, relPaths []string, now time.Time) (string, error) {
+	backupDir := filepath.Join(targetDir, backupDirPrefix+now.Format("20060102-150405"))
+
+	created := false
+	for _, rel := range relPaths {
+		src := filepath.Join(targetDir, rel)
+		info, err := os.Stat(src)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		if !created {
+			if _, err := os.Stat(backupDir); err == nil {
+				return "", fmt.Errorf("backup directory %s already exists", backupDir)
+			}
+			created = true
+		}
+
+		dest := filepath.Join(backupDir, rel)
+		if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+			return "", fmt.Errorf("failed to create backup directory: %w", err)
+		}
+
+		content, err := os.ReadFile(src)
+		if err != nil {
+			return "", fmt.Errorf("failed to read %s: %w", src, err)
+		}
+		if err := os.WriteFile(dest, content, info.Mode().Perm()); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", dest, err)
+		}
+	}
+
+	if !created {
+		return "", nil
+	}
+	return backupDir, nil
+}
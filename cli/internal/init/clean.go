@@ -0,0 +1,126 @@
+package init
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CleanOptions controls how Clean removes a project's FionaCode files
+type CleanOptions struct {
+	// Force removes files even if they differ from the source or weren't
+	// created by init
+	Force bool
+	// Backup copies every file into a timestamped backup directory first
+	Backup bool
+	// Source is what the files are compared against; nil means the
+	// embedded assets
+	Source Source
+}
+
+// CleanResult describes what Clean removed
+type CleanResult struct {
+	// Removed lists the removed files, slash-separated and relative to the
+	// project root
+	Removed []string
+	// BackupDir is the directory holding copies of the removed files, or ""
+	BackupDir string
+}
+
+// ErrUserFiles is returned by Clean when the project contains files init
+// wouldn't have created; use CleanOptions.Force to remove them anyway
+var ErrUserFiles = errors.New("project contains modified or additional files")
+
+// Clean removes opencode.json and the .opencode directory from targetDir.
+// Unless opts.Force is set, it refuses to remove anything when a file
+// differs from the source or wasn't created by init, so user data is never
+// lost by accident.
+func Clean(targetDir string, opts CleanOptions) (*CleanResult, error) {
+	if targetDir == "" {
+		var err error
+		targetDir, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+
+	src := opts.Source
+	if src == nil {
+		src = EmbeddedSource()
+	}
+
+	files, err := cleanableFiles(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return &CleanResult{}, nil
+	}
+
+	if !opts.Force {
+		diffs, err := Diff(targetDir, src)
+		if err != nil {
+			return nil, err
+		}
+		identical := make(map[string]bool)
+		for _, diff := range diffs {
+			if diff.Status == StatusIdentical {
+				identical[diff.Path] = true
+			}
+		}
+
+		// The manifest is written by init, not copied from the source
+		identical[ManifestFile] = true
+
+		var userFiles []string
+		for _, file := range files {
+			if !identical[file] {
+				userFiles = append(userFiles, file)
+			}
+		}
+		if len(userFiles) > 0 {
+			return nil, fmt.Errorf("%w:\n  %s", ErrUserFiles, strings.Join(userFiles, "\n  "))
+		}
+	}
+
+	result := &CleanResult{}
+	if opts.Backup {
+		rels := make([]string, len(files))
+		for i, file := range files {
+			rels[i] = filepath.FromSlash(file)
+		}
+		result.BackupDir, err = backupFiles(targetDir, rels, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up files: %w", err)
+		}
+	}
+
+	if err := os.Remove(filepath.Join(targetDir, "opencode.json")); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove opencode.json: %w", err)
+	}
+	if err := os.RemoveAll(filepath.Join(targetDir, ".opencode")); err != nil {
+		return nil, fmt.Errorf("failed to remove .opencode: %w", err)
+	}
+
+	result.Removed = files
+	return result, nil
+}
+
+// cleanableFiles returns opencode.json and every file below .opencode that
+// exist in targetDir
+func cleanableFiles(targetDir string) ([]string, error) {
+	var files []string
+	if _, err := os.Stat(filepath.Join(targetDir, "opencode.json")); err == nil {
+		files = append(files, "opencode.json")
+	}
+
+	opencodeFiles, err := walkProjectFiles(targetDir, ".opencode")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("failed to read .opencode: %w", err)
+	}
+	return append(files, opencodeFiles...), nil
+}
@@ -0,0 +1,51 @@
+package init
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Component is a part of the FionaCode framework that Initialize can scaffold
+type Component string
+
+const (
+	// ComponentConfig is opencode.json
+	ComponentConfig Component = "config"
+	// ComponentPrompts is the .opencode/prompts directory
+	ComponentPrompts Component = "prompts"
+	// ComponentTool is the .opencode/tool directory
+	ComponentTool Component = "tool"
+)
+
+// Components returns every component in scaffolding order
+func Components() []Component {
+	return []Component{ComponentConfig, ComponentPrompts, ComponentTool}
+}
+
+// ParseComponent converts a component name into a Component
+func ParseComponent(name string) (Component, error) {
+	name = strings.TrimSpace(name)
+	for _, c := range Components() {
+		if string(c) == name {
+			return c, nil
+		}
+	}
+
+	names := make([]string, 0, len(Components()))
+	for _, c := range Components() {
+		names = append(names, string(c))
+	}
+	return "", fmt.Errorf("unknown component %q (valid: %s)", name, strings.Join(names, ", "))
+}
+
+// path returns the project-relative path a component occupies
+func (c Component) path() string {
+	switch c {
+	case ComponentPrompts:
+		return ".opencode/prompts"
+	case ComponentTool:
+		return ".opencode/tool"
+	default:
+		return "opencode.json"
+	}
+}
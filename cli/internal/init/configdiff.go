@@ -0,0 +1,86 @@
+package init
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+)
+
+// EntryDiff is how a named opencode.json entry, such as an agent, compares
+// to the source. Statuses mean the same as for files.
+type EntryDiff struct {
+	Name   string
+	Status FileStatus
+}
+
+// ConfigDiff lists the agents and tools that differ between a project's
+// opencode.json and a source's
+type ConfigDiff struct {
+	Agents []EntryDiff
+	Tools  []EntryDiff
+}
+
+// configSections holds the opencode.json sections DiffConfig compares
+type configSections struct {
+	Agent map[string]interface{} `json:"agent"`
+	Tools map[string]interface{} `json:"tools"`
+}
+
+// DiffConfig compares the agent and tools sections of the opencode.json in
+// targetDir against src's (the embedded assets when nil). Entries are
+// compared by value, so formatting and key order don't matter; identical
+// entries are left out. Each list is sorted by name.
+func DiffConfig(targetDir string, src Source) (*ConfigDiff, error) {
+	if src == nil {
+		src = EmbeddedSource()
+	}
+
+	content, err := os.ReadFile(validate.ConfigPath(targetDir))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read opencode.json: %w", err)
+	}
+	var project configSections
+	if err := json.Unmarshal(validate.StripComments(content), &project); err != nil {
+		return nil, fmt.Errorf("failed to parse opencode.json: %w", err)
+	}
+
+	content, err = src.ReadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read source opencode.json: %w", err)
+	}
+	var source configSections
+	if err := json.Unmarshal(validate.StripComments(content), &source); err != nil {
+		return nil, fmt.Errorf("failed to parse source opencode.json: %w", err)
+	}
+
+	return &ConfigDiff{
+		Agents: diffEntries(project.Agent, source.Agent),
+		Tools:  diffEntries(project.Tools, source.Tools),
+	}, nil
+}
+
+// diffEntries compares the entries of one section, leaving out identical ones
+func diffEntries(project, source map[string]interface{}) []EntryDiff {
+	var diffs []EntryDiff
+	for name, sourceValue := range source {
+		projectValue, ok := project[name]
+		switch {
+		case !ok:
+			diffs = append(diffs, EntryDiff{Name: name, Status: StatusAdded})
+		case !reflect.DeepEqual(projectValue, sourceValue):
+			diffs = append(diffs, EntryDiff{Name: name, Status: StatusModified})
+		}
+	}
+	for name := range project {
+		if _, ok := source[name]; !ok {
+			diffs = append(diffs, EntryDiff{Name: name, Status: StatusRemoved})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	return diffs
+}
@@ -0,0 +1,152 @@
+package init
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+)
+
+// FileStatus is how a project file compares to the source it came from
+type FileStatus string
+
+const (
+	// StatusIdentical means the project file matches the source
+	StatusIdentical FileStatus = "identical"
+	// StatusModified means the project file differs from the source
+	StatusModified FileStatus = "modified"
+	// StatusAdded means the source has a file the project doesn't
+	StatusAdded FileStatus = "added"
+	// StatusRemoved means the project has a file the source no longer ships
+	StatusRemoved FileStatus = "removed"
+)
+
+// FileDiff is the comparison result for a single file
+type FileDiff struct {
+	// Path is slash-separated and relative to the project root
+	Path   string
+	Status FileStatus
+}
+
+// Diff compares opencode.json, .opencode/prompts and .opencode/tool in
+// targetDir against src (the embedded assets when nil) by content hash.
+// The result is sorted by path.
+func Diff(targetDir string, src Source) ([]FileDiff, error) {
+	if src == nil {
+		src = EmbeddedSource()
+	}
+
+	sourceHashes, err := sourceFileHashes(src)
+	if err != nil {
+		return nil, err
+	}
+	projectHashes, err := projectFileHashes(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []FileDiff
+	for path, sourceHash := range sourceHashes {
+		projectHash, ok := projectHashes[path]
+		switch {
+		case !ok:
+			diffs = append(diffs, FileDiff{Path: path, Status: StatusAdded})
+		case projectHash == sourceHash:
+			diffs = append(diffs, FileDiff{Path: path, Status: StatusIdentical})
+		default:
+			diffs = append(diffs, FileDiff{Path: path, Status: StatusModified})
+		}
+	}
+	for path := range projectHashes {
+		if _, ok := sourceHashes[path]; !ok {
+			diffs = append(diffs, FileDiff{Path: path, Status: StatusRemoved})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+// sourceFileHashes hashes opencode.json and every prompt and tool file the
+// source provides, keyed by project-relative path
+func sourceFileHashes(src Source) (map[string][sha256.Size]byte, error) {
+	hashes := make(map[string][sha256.Size]byte)
+
+	config, err := src.ReadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read opencode.json: %w", err)
+	}
+	hashes["opencode.json"] = sha256.Sum256(config)
+
+	files, err := sourceFiles(src)
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		content, err := src.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		hashes[file] = sha256.Sum256(content)
+	}
+	return hashes, nil
+}
+
+// sourceFiles returns the prompt and tool files the source provides
+func sourceFiles(src Source) ([]string, error) {
+	promptFiles, err := src.PromptFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list prompt files: %w", err)
+	}
+	toolFiles, err := src.ToolFiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tool files: %w", err)
+	}
+	return append(promptFiles, toolFiles...), nil
+}
+
+// projectFileHashes hashes opencode.json (or opencode.jsonc) and the files below the project's
+// prompts and tool directories, keyed by project-relative path. Missing
+// files and directories are skipped.
+func projectFileHashes(targetDir string) (map[string][sha256.Size]byte, error) {
+	hashes := make(map[string][sha256.Size]byte)
+
+	var files []string
+	for _, dir := range []string{".opencode/prompts", ".opencode/tool"} {
+		dirFiles, err := walkProjectFiles(targetDir, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		files = append(files, dirFiles...)
+	}
+
+	// The project's config may be opencode.jsonc; it is still compared with
+	// the source's opencode.json
+	config, err := os.ReadFile(validate.ConfigPath(targetDir))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read opencode.json: %w", err)
+	}
+	if err == nil {
+		hashes["opencode.json"] = sha256.Sum256(config)
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(targetDir, filepath.FromSlash(file)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", file, err)
+		}
+		hashes[file] = sha256.Sum256(content)
+	}
+	return hashes, nil
+}
@@ -0,0 +1,127 @@
+package init
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envRefPattern matches OpenCode's {env:NAME} substitutions
+var envRefPattern = regexp.MustCompile(`\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// mcpEnvConfig is the part of opencode.json that references environment
+// variables: env maps of mcpServers entries, and environment maps plus
+// {env:NAME} substitutions of mcp entries
+type mcpEnvConfig struct {
+	MCPServers map[string]struct {
+		Env map[string]string `json:"env"`
+	} `json:"mcpServers"`
+	MCP map[string]json.RawMessage `json:"mcp"`
+}
+
+// mcpEnvVars returns the environment variables the MCP servers in config
+// use, mapped to the sorted names of the servers using them
+func mcpEnvVars(config []byte) (map[string][]string, error) {
+	var parsed mcpEnvConfig
+	if err := json.Unmarshal(config, &parsed); err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string][]string)
+	use := func(name, server string) {
+		for _, s := range vars[name] {
+			if s == server {
+				return
+			}
+		}
+		vars[name] = append(vars[name], server)
+	}
+
+	for server, def := range parsed.MCPServers {
+		for name := range def.Env {
+			use(name, server)
+		}
+	}
+	for server, raw := range parsed.MCP {
+		var def struct {
+			Environment map[string]string `json:"environment"`
+		}
+		if err := json.Unmarshal(raw, &def); err == nil {
+			for name := range def.Environment {
+				use(name, server)
+			}
+		}
+		for _, match := range envRefPattern.FindAllSubmatch(raw, -1) {
+			use(string(match[1]), server)
+		}
+	}
+
+	for name := range vars {
+		sort.Strings(vars[name])
+	}
+	return vars, nil
+}
+
+// stageEnvExample adds every environment variable the MCP servers in
+// config use to .env.example with a blank value, keeping variables already
+// listed there. It returns the names of the variables it added.
+func stageEnvExample(tx *transaction, config []byte) ([]string, error) {
+	existing, err := os.ReadFile(filepath.Join(tx.targetDir, ".env.example"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	content, names, err := envExampleContent(existing, config)
+	if err != nil || len(names) == 0 {
+		return nil, err
+	}
+	if err := tx.WriteFile(".env.example", content, 0644); err != nil {
+		return nil, err
+	}
+	return names, nil
+}
+
+// envExampleContent returns existing with a blank line for every variable
+// the MCP servers in config use that it doesn't list yet, along with the
+// sorted names of the variables added
+func envExampleContent(existing, config []byte) ([]byte, []string, error) {
+	vars, err := mcpEnvVars(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, line := range strings.Split(string(existing), "\n") {
+		if name, _, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+			delete(vars, strings.TrimSpace(name))
+		}
+	}
+	if len(vars) == 0 {
+		return nil, nil, nil
+	}
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	if len(existing) > 0 {
+		b.Write(existing)
+		if !strings.HasSuffix(string(existing), "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	} else {
+		b.WriteString("# Environment variables used by the MCP servers in opencode.json.\n")
+		b.WriteString("# Copy this file to .env and fill in the values.\n\n")
+	}
+	for _, name := range names {
+		b.WriteString("# Used by: " + strings.Join(vars[name], ", ") + "\n")
+		b.WriteString(name + "=\n")
+	}
+	return []byte(b.String()), names, nil
+}
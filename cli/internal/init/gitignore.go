@@ -0,0 +1,67 @@
+package init
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// gitignoreEntries keeps secrets and local state out of version control
+var gitignoreEntries = []string{
+	".env",
+	".env.local",
+	".opencode/node_modules/",
+	backupDirPrefix + "*/",
+}
+
+// stageGitignore adds the missing gitignoreEntries to the project's
+// .gitignore, creating it if needed. Existing lines are never changed, so
+// running it again is a no-op. It reports whether anything was staged.
+func stageGitignore(tx *transaction) (bool, error) {
+	existing, err := os.ReadFile(filepath.Join(tx.targetDir, ".gitignore"))
+	if err != nil && !os.IsNotExist(err) {
+		return false, err
+	}
+
+	content := gitignoreContent(existing)
+	if content == nil {
+		return false, nil
+	}
+	if err := tx.WriteFile(".gitignore", content, 0644); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// gitignoreContent returns existing with the missing gitignoreEntries
+// appended, or nil if none are missing
+func gitignoreContent(existing []byte) []byte {
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		present[strings.TrimSpace(line)] = true
+	}
+
+	var missing []string
+	for _, entry := range gitignoreEntries {
+		if !present[entry] {
+			missing = append(missing, entry)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	b.Write(existing)
+	if len(existing) > 0 {
+		if !strings.HasSuffix(string(existing), "\n") {
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("# FionaCode\n")
+	for _, entry := range missing {
+		b.WriteString(entry + "\n")
+	}
+	return []byte(b.String())
+}
@@ -1,117 +1,420 @@
 package init
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/dscv103/fionacode/cli/internal/assets"
+	"golang.org/x/sync/errgroup"
 )
 
-// Initialize creates opencode.json and .opencode directory in the target directory
-func Initialize(targetDir string) error {
+// Options controls how Initialize scaffolds a project
+type Options struct {
+	// Merge adds missing embedded entries to an existing opencode.json and
+	// copies missing prompt and tool files instead of refusing to run
+	Merge bool
+	// Force overwrites an existing opencode.json and .opencode files
+	Force bool
+	// Into allows initializing inside an existing project: instead of
+	// refusing to run when opencode.json or .opencode exist, only the files
+	// that would be created are checked for conflicts. Other files in the
+	// directory are never touched.
+	Into bool
+	// NoBackup skips backing up files that Force is about to overwrite
+	NoBackup bool
+	// Only limits scaffolding to the given components; empty means all
+	Only []Component
+	// Source provides the files to copy; nil means the embedded assets
+	Source Source
+	// GitIgnore adds entries for secrets and local state to .gitignore
+	GitIgnore bool
+	// EnvExample lists the environment variables MCP servers use in .env.example
+	EnvExample bool
+	// NormalizeJSON rewrites opencode.json with 2-space indentation and a
+	// trailing newline instead of copying the source's formatting
+	NormalizeJSON bool
+	// Manifest writes .opencode/manifest.json with the SHA256 of every
+	// file created (see Verify)
+	Manifest bool
+	// Version is the fifi version recorded in the manifest
+	Version string
+	// Preset limits the agents, files and MCP servers copied to a bundled
+	// profile (see assets.Presets); empty means assets.DefaultPreset
+	Preset string
+	// Agents limits opencode.json to the named agents, after the preset is
+	// applied, and the prompt files to the ones they reference; empty means
+	// every agent
+	Agents []string
+}
+
+// includes reports whether the component c should be scaffolded
+func (o Options) includes(c Component) bool {
+	if len(o.Only) == 0 {
+		return true
+	}
+	for _, only := range o.Only {
+		if only == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Result describes what Initialize did
+type Result struct {
+	// Merged is true if an existing opencode.json was merged
+	Merged bool
+	// Conflicts lists config entries (e.g. "agent.orchestrator") that exist
+	// with a different definition and were left untouched during a merge
+	Conflicts []string
+	// BackupDir is the directory holding copies of overwritten files, or ""
+	// if no backup was made
+	BackupDir string
+	// GitIgnoreUpdated is true if .gitignore was created or extended
+	GitIgnoreUpdated bool
+	// EnvExampleVars lists the variables added to .env.example
+	EnvExampleVars []string
+	// PromptFiles and ToolFiles count the prompt and tool files written
+	PromptFiles int
+	ToolFiles   int
+	// Files lists the absolute path of every file written, including
+	// opencode.json, sorted. For Archive it lists the entry names instead.
+	Files []string
+}
+
+// Initialize creates opencode.json and .opencode directory in the target directory.
+//
+// All files are staged first and moved into place only once every copy has
+// succeeded; on error the staging area and any partial output are removed.
+func Initialize(targetDir string, opts Options) (result *Result, err error) {
+	// Fail before anything is created if the target can't be written to
+	if err := CheckWritable(targetDir); err != nil {
+		return nil, err
+	}
+
 	// Resolve target directory
 	if targetDir == "" {
-		var err error
 		targetDir, err = os.Getwd()
 		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
 		}
 	} else {
-		// Create target directory if it doesn't exist
-		if err := os.MkdirAll(targetDir, 0755); err != nil {
-			return fmt.Errorf("failed to create target directory: %w", err)
+		// Create target directory if it doesn't exist, removing it again if init fails
+		var createdDirs []string
+		createdDirs, err = mkdirAllTracked(targetDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create target directory: %w", err)
 		}
+		defer func() {
+			if err != nil {
+				for i := len(createdDirs) - 1; i >= 0; i-- {
+					os.Remove(createdDirs[i])
+				}
+			}
+		}()
 	}
 
-	// Check if opencode.json already exists
-	opencodeJSONPath := filepath.Join(targetDir, "opencode.json")
-	if _, err := os.Stat(opencodeJSONPath); err == nil {
-		return fmt.Errorf("opencode.json already exists in %s", targetDir)
+	src, err := resolveSource(opts)
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if .opencode directory already exists
-	opencodeDirPath := filepath.Join(targetDir, ".opencode")
-	if _, err := os.Stat(opencodeDirPath); err == nil {
-		return fmt.Errorf(".opencode directory already exists in %s", targetDir)
+	result = &Result{}
+
+	opencodeJSONPath := filepath.Join(targetDir, "opencode.json")
+	_, statErr := os.Stat(opencodeJSONPath)
+	configExists := statErr == nil
+
+	if opts.Into && !opts.Merge && !opts.Force {
+		plan, err := Plan(targetDir, opts)
+		if err != nil {
+			return nil, err
+		}
+		if paths := conflicts(plan); len(paths) > 0 {
+			return nil, &ConflictError{Paths: paths}
+		}
+	} else if !opts.Merge && !opts.Force {
+		if len(opts.Only) == 0 {
+			// Check if opencode.json already exists
+			if configExists {
+				return nil, fmt.Errorf("opencode.json already exists in %s", targetDir)
+			}
+
+			// Check if .opencode directory already exists
+			if _, err := os.Stat(filepath.Join(targetDir, ".opencode")); err == nil {
+				return nil, fmt.Errorf(".opencode directory already exists in %s", targetDir)
+			}
+		} else {
+			// Only guard the components being written
+			for _, c := range opts.Only {
+				if _, err := os.Stat(filepath.Join(targetDir, filepath.FromSlash(c.path()))); err == nil {
+					return nil, fmt.Errorf("%s already exists in %s", c.path(), targetDir)
+				}
+			}
+		}
 	}
 
-	// Copy opencode.json
-	if err := copyOpencodeJSON(targetDir); err != nil {
-		return fmt.Errorf("failed to copy opencode.json: %w", err)
+	// Back up everything --force is about to overwrite
+	if opts.Force && !opts.NoBackup {
+		dests, err := sourceFileDests(src, opts)
+		if err != nil {
+			return nil, err
+		}
+		for i, dest := range dests {
+			dests[i] = filepath.FromSlash(dest)
+		}
+		backupDir, err := backupFiles(targetDir, dests, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up existing files: %w", err)
+		}
+		result.BackupDir = backupDir
 	}
 
-	// Create .opencode directory structure
-	if err := os.MkdirAll(filepath.Join(targetDir, ".opencode", "prompts"), 0755); err != nil {
-		return fmt.Errorf("failed to create .opencode/prompts directory: %w", err)
+	tx, err := newTransaction(targetDir)
+	if err != nil {
+		return nil, err
 	}
-	if err := os.MkdirAll(filepath.Join(targetDir, ".opencode", "tool"), 0755); err != nil {
-		return fmt.Errorf("failed to create .opencode/tool directory: %w", err)
+	defer tx.Abort()
+
+	if opts.includes(ComponentConfig) {
+		if configExists && opts.Merge {
+			// Merge the source's agents, tools and MCP servers into the existing config
+			conflicts, err := stageMergedOpencodeJSON(tx, src, opencodeJSONPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to merge opencode.json: %w", err)
+			}
+			result.Merged = true
+			result.Conflicts = conflicts
+		} else {
+			// Copy opencode.json
+			if err := copyOpencodeJSON(tx, src, opts.NormalizeJSON); err != nil {
+				return nil, fmt.Errorf("failed to copy opencode.json: %w", err)
+			}
+		}
 	}
 
+	// Keep existing prompt and tool files when merging, unless forced
+	skipExisting := opts.Merge && !opts.Force
+
 	// Copy prompt files
-	if err := copyPromptFiles(targetDir); err != nil {
-		return fmt.Errorf("failed to copy prompt files: %w", err)
+	if opts.includes(ComponentPrompts) {
+		result.PromptFiles, err = copyPromptFiles(tx, src, skipExisting)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy prompt files: %w", err)
+		}
+		// Create the directory even if every file was skipped
+		tx.MkdirAll(filepath.Join(".opencode", "prompts"))
 	}
 
 	// Copy tool files
-	if err := copyToolFiles(targetDir); err != nil {
-		return fmt.Errorf("failed to copy tool files: %w", err)
+	if opts.includes(ComponentTool) {
+		result.ToolFiles, err = copyToolFiles(tx, src, skipExisting)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy tool files: %w", err)
+		}
+		tx.MkdirAll(filepath.Join(".opencode", "tool"))
 	}
 
-	return nil
-}
+	if opts.EnvExample {
+		config, err := src.ReadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read opencode.json: %w", err)
+		}
+		result.EnvExampleVars, err = stageEnvExample(tx, config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write .env.example: %w", err)
+		}
+	}
 
-func copyOpencodeJSON(targetDir string) error {
-	content, err := assets.GetOpencodeJSON()
+	if opts.GitIgnore {
+		result.GitIgnoreUpdated, err = stageGitignore(tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update .gitignore: %w", err)
+		}
+	}
+
+	if opts.Manifest {
+		if err := stageManifest(tx, opts.Version); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", ManifestFile, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	absDir, err := filepath.Abs(targetDir)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	for _, rel := range tx.Files() {
+		result.Files = append(result.Files, filepath.Join(absDir, rel))
 	}
 
-	destPath := filepath.Join(targetDir, "opencode.json")
-	return os.WriteFile(destPath, content, 0644)
+	return result, nil
 }
 
-func copyPromptFiles(targetDir string) error {
-	promptFiles, err := assets.GetPromptFiles()
+// resolveSource returns the source selected by opts, limited to its preset
+// and agents and checked to provide every selected component
+func resolveSource(opts Options) (Source, error) {
+	src := opts.Source
+	if src == nil {
+		src = EmbeddedSource()
+	}
+	preset := opts.Preset
+	if preset == "" {
+		preset = assets.DefaultPreset
+	}
+	src, err := withPreset(src, preset)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if len(opts.Agents) > 0 {
+		if src, err = withAgents(src, opts.Agents); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkSource(src, opts); err != nil {
+		return nil, fmt.Errorf("invalid template source: %w", err)
 	}
+	return src, nil
+}
 
-	for _, file := range promptFiles {
-		content, err := assets.ReadFile(file)
+// sourceFileDests returns the project-relative destination of every source
+// file of the components selected by opts
+func sourceFileDests(src Source, opts Options) ([]string, error) {
+	var dests []string
+	if opts.includes(ComponentConfig) {
+		dests = append(dests, "opencode.json")
+	}
+	if opts.includes(ComponentPrompts) {
+		promptFiles, err := src.PromptFiles()
+		if err != nil {
+			return nil, err
+		}
+		dests = append(dests, promptFiles...)
+	}
+	if opts.includes(ComponentTool) {
+		toolFiles, err := src.ToolFiles()
 		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
+			return nil, err
 		}
+		dests = append(dests, toolFiles...)
+	}
+	return dests, nil
+}
+
+// copyOpencodeJSON stages the source's opencode.json, reformatted when
+// normalize is set
+func copyOpencodeJSON(tx *transaction, src Source, normalize bool) error {
+	content, err := src.ReadConfig()
+	if err != nil {
+		return err
+	}
 
-		// Strip "embedded/" prefix from the path
-		destPath := filepath.Join(targetDir, file[9:]) // "embedded/" is 9 characters
-		if err := os.WriteFile(destPath, content, 0644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", destPath, err)
+	if normalize {
+		if content, err = normalizeJSON(content); err != nil {
+			return err
 		}
 	}
+	return tx.WriteFile("opencode.json", content, 0644)
+}
 
-	return nil
+// normalizeJSON reformats content with 2-space indentation and a trailing
+// newline. Keys keep their order and every field is kept, including ones
+// fifi doesn't model.
+func normalizeJSON(content []byte) ([]byte, error) {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, content); err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, compact.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
 }
 
-func copyToolFiles(targetDir string) error {
-	toolFiles, err := assets.GetToolFiles()
+// copyPromptFiles copies the source's prompt files, keeping existing files when skipExisting is set,
+// and returns how many were copied
+func copyPromptFiles(tx *transaction, src Source, skipExisting bool) (int, error) {
+	promptFiles, err := src.PromptFiles()
 	if err != nil {
-		return err
+		return 0, err
 	}
+	return copyFiles(tx, src, promptFiles, skipExisting)
+}
 
-	for _, file := range toolFiles {
-		content, err := assets.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("failed to read %s: %w", file, err)
+// copyToolFiles copies the source's tool files, keeping existing files when skipExisting is set,
+// and returns how many were copied
+func copyToolFiles(tx *transaction, src Source, skipExisting bool) (int, error) {
+	toolFiles, err := src.ToolFiles()
+	if err != nil {
+		return 0, err
+	}
+	return copyFiles(tx, src, toolFiles, skipExisting)
+}
+
+// copyConcurrency bounds how many files copyFiles copies at once
+const copyConcurrency = 8
+
+// copyFiles copies files from the source into the transaction in parallel,
+// stopping at the first error, and returns how many were copied
+func copyFiles(tx *transaction, src Source, files []string, skipExisting bool) (int, error) {
+	var pending []string
+	for _, file := range files {
+		if skipExisting {
+			if _, err := os.Stat(filepath.Join(tx.targetDir, filepath.FromSlash(file))); err == nil {
+				continue
+			}
 		}
+		pending = append(pending, file)
+	}
 
-		// Strip "embedded/" prefix from the path
-		destPath := filepath.Join(targetDir, file[9:]) // "embedded/" is 9 characters
-		if err := os.WriteFile(destPath, content, 0644); err != nil {
-			return fmt.Errorf("failed to write %s: %w", destPath, err)
+	// Create every staging directory up front so the workers never race on it
+	for _, file := range pending {
+		if err := tx.PrepareDir(filepath.FromSlash(file)); err != nil {
+			return 0, err
 		}
 	}
 
-	return nil
+	g, ctx := errgroup.WithContext(context.Background())
+	g.SetLimit(copyConcurrency)
+	for _, file := range pending {
+		g.Go(func() error {
+			// Don't start new copies once one has failed
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			return copyFile(tx, src, file)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return 0, err
+	}
+	return len(pending), nil
+}
+
+// copyFile streams a source file into the transaction without loading it
+// into memory, keeping executable tools executable
+func copyFile(tx *transaction, src Source, file string) error {
+	mode, err := src.FileMode(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+
+	in, err := src.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer in.Close()
+
+	logger.Debug("copying file", "file", file, "mode", mode.String())
+	return tx.CopyFile(filepath.FromSlash(file), in, mode)
 }
@@ -0,0 +1,11 @@
+package init
+
+import "log/slog"
+
+// logger receives debug logs of every file written; see SetLogger
+var logger = slog.Default()
+
+// SetLogger sets the logger the package writes its debug logs to
+func SetLogger(l *slog.Logger) {
+	logger = l
+}
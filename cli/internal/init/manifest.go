@@ -0,0 +1,178 @@
+package init
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestFile is the project-relative path of the manifest written by
+// Initialize when Options.Manifest is set
+const ManifestFile = ".opencode/manifest.json"
+
+// StatusMissing and StatusUntracked are reported by Verify
+const (
+	// StatusMissing means the manifest lists a file the project doesn't have
+	StatusMissing FileStatus = "missing"
+	// StatusUntracked means the project has a prompt or tool file the
+	// manifest doesn't list
+	StatusUntracked FileStatus = "untracked"
+)
+
+// Manifest records the SHA256 of every file init created, so later edits
+// can be detected
+type Manifest struct {
+	// Version is the fifi version that wrote the files
+	Version string `json:"version"`
+	// Files maps slash-separated project-relative paths to hex SHA256 hashes
+	Files map[string]string `json:"files"`
+}
+
+// ErrNoManifest is returned by Verify when the project has no manifest
+var ErrNoManifest = errors.New("no " + ManifestFile + " found; run 'fifi init --manifest' to create one")
+
+// ReadManifest reads the manifest of the project in targetDir
+func ReadManifest(targetDir string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(targetDir, filepath.FromSlash(ManifestFile)))
+	if os.IsNotExist(err) {
+		return nil, ErrNoManifest
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ManifestFile, err)
+	}
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]string)
+	}
+	return &manifest, nil
+}
+
+// manifestTracked reports whether the slash-separated project-relative
+// path belongs in the manifest: opencode.json and the prompt and tool
+// files. .gitignore and .env.example are meant to be edited, so they aren't.
+func manifestTracked(path string) bool {
+	return path == "opencode.json" ||
+		strings.HasPrefix(path, ".opencode/prompts/") ||
+		strings.HasPrefix(path, ".opencode/tool/")
+}
+
+// stageManifest stages a manifest hashing every tracked file in tx. Entries
+// of an existing manifest for files this run didn't write are kept, so
+// --merge and --only don't drop them.
+func stageManifest(tx *transaction, version string) error {
+	manifest, err := ReadManifest(tx.targetDir)
+	if errors.Is(err, ErrNoManifest) {
+		manifest = &Manifest{Files: make(map[string]string)}
+	} else if err != nil {
+		return err
+	}
+	manifest.Version = version
+
+	for _, rel := range tx.Files() {
+		path := filepath.ToSlash(rel)
+		if !manifestTracked(path) {
+			continue
+		}
+		f, err := tx.OpenStaged(rel)
+		if err != nil {
+			return err
+		}
+		hash, err := hashReader(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("failed to hash %s: %w", path, err)
+		}
+		manifest.Files[path] = hash
+	}
+
+	content, err := marshalManifest(manifest)
+	if err != nil {
+		return err
+	}
+	return tx.WriteFile(filepath.FromSlash(ManifestFile), content, 0644)
+}
+
+// marshalManifest encodes a manifest with sorted keys and a trailing newline
+func marshalManifest(manifest *Manifest) ([]byte, error) {
+	if manifest.Files == nil {
+		manifest.Files = make(map[string]string)
+	}
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(content, '\n'), nil
+}
+
+// hashReader returns the hex SHA256 of everything read from r
+func hashReader(r io.Reader) (string, error) {
+	hash := sha256.New()
+	if _, err := io.Copy(hash, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Verify compares the files in targetDir against the hashes recorded in
+// its manifest. Files listed in the manifest are reported as identical,
+// modified or missing; prompt and tool files it doesn't list as untracked.
+// The result is sorted by path.
+func Verify(targetDir string) (*Manifest, []FileDiff, error) {
+	manifest, err := ReadManifest(targetDir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var diffs []FileDiff
+	for path, want := range manifest.Files {
+		f, err := os.Open(filepath.Join(targetDir, filepath.FromSlash(path)))
+		if os.IsNotExist(err) {
+			diffs = append(diffs, FileDiff{Path: path, Status: StatusMissing})
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		got, err := hashReader(f)
+		f.Close()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		status := StatusIdentical
+		if got != want {
+			status = StatusModified
+		}
+		diffs = append(diffs, FileDiff{Path: path, Status: status})
+	}
+
+	for _, dir := range []string{".opencode/prompts", ".opencode/tool"} {
+		files, err := walkProjectFiles(targetDir, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, file := range files {
+			if _, ok := manifest.Files[file]; !ok {
+				diffs = append(diffs, FileDiff{Path: file, Status: StatusUntracked})
+			}
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return manifest, diffs, nil
+}
@@ -0,0 +1,152 @@
+package init
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+)
+
+// mergeConfig merges the embedded opencode.json into an existing one.
+//
+// Top-level objects (agent, tools, MCP servers, ...) are merged entry by
+// entry: entries missing from the existing config are added, entries that
+// already exist are left untouched. An existing entry whose definition
+// differs from the embedded one is reported as a conflict and skipped.
+// Top-level keys that aren't objects are only added when missing.
+func mergeConfig(existing, embedded []byte) ([]byte, []string, error) {
+	var current map[string]json.RawMessage
+	if err := json.Unmarshal(existing, &current); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse existing opencode.json: %w", err)
+	}
+	if current == nil {
+		current = make(map[string]json.RawMessage)
+	}
+
+	var incoming map[string]json.RawMessage
+	if err := json.Unmarshal(embedded, &incoming); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse template opencode.json: %w", err)
+	}
+
+	var conflicts []string
+	for _, key := range sortedKeys(incoming) {
+		value := incoming[key]
+
+		existingValue, ok := current[key]
+		if !ok {
+			current[key] = value
+			continue
+		}
+
+		existingSection, okExisting := asObject(existingValue)
+		incomingSection, okIncoming := asObject(value)
+		if !okExisting || !okIncoming {
+			continue
+		}
+
+		for _, name := range sortedKeys(incomingSection) {
+			entry, ok := existingSection[name]
+			if !ok {
+				existingSection[name] = incomingSection[name]
+				continue
+			}
+			if !jsonEqual(entry, incomingSection[name]) {
+				conflicts = append(conflicts, key+"."+name)
+			}
+		}
+
+		merged, err := marshalJSON(existingSection, "")
+		if err != nil {
+			return nil, nil, err
+		}
+		current[key] = merged
+	}
+
+	// Marshaling maps sorts keys, so the output is stable across runs
+	merged, err := marshalJSON(current, "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return merged, conflicts, nil
+}
+
+// marshalJSON encodes v without HTML escaping, indenting when indent is set
+func marshalJSON(v interface{}, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if indent != "" {
+		enc.SetIndent("", indent)
+	}
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	out := buf.Bytes()
+	if indent == "" {
+		out = bytes.TrimRight(out, "\n")
+	}
+	return out, nil
+}
+
+// stageMergedOpencodeJSON merges the source config into the config at path,
+// opencode.json or opencode.jsonc, and stages the result under the same
+// name. The merged file is written without the existing file's comments.
+func stageMergedOpencodeJSON(tx *transaction, src Source, path string) ([]string, error) {
+	existing, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	existing = validate.StripComments(existing)
+
+	embedded, err := src.ReadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template opencode.json: %w", err)
+	}
+
+	merged, conflicts, err := mergeConfig(existing, embedded)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.WriteFile(filepath.Base(path), merged, 0644); err != nil {
+		return nil, err
+	}
+	return conflicts, nil
+}
+
+// asObject decodes raw as a JSON object, reporting false if it isn't one
+func asObject(raw json.RawMessage) (map[string]json.RawMessage, bool) {
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &obj); err != nil || obj == nil {
+		return nil, false
+	}
+	return obj, true
+}
+
+// jsonEqual reports whether two JSON documents are semantically equal
+func jsonEqual(a, b json.RawMessage) bool {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return false
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+func sortedKeys(m map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,88 @@
+package init
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergeConfig(t *testing.T) {
+	tests := []struct {
+		name          string
+		existing      string
+		embedded      string
+		want          string
+		wantConflicts []string
+		wantErr       bool
+	}{
+		{
+			name:     "missing keys are added",
+			existing: `{"theme": "dark"}`,
+			embedded: `{"agent": {"docs": {"model": "m"}}}`,
+			want:     `{"theme": "dark", "agent": {"docs": {"model": "m"}}}`,
+		},
+		{
+			name:     "missing entries are added to existing sections",
+			existing: `{"agent": {"mine": {"model": "x"}}}`,
+			embedded: `{"agent": {"docs": {"model": "m"}}}`,
+			want:     `{"agent": {"mine": {"model": "x"}, "docs": {"model": "m"}}}`,
+		},
+		{
+			name:     "identical entries aren't conflicts",
+			existing: `{"tools": {"bash": true}}`,
+			embedded: `{"tools": {"bash": true}}`,
+			want:     `{"tools": {"bash": true}}`,
+		},
+		{
+			name:          "differing entries are kept and reported",
+			existing:      `{"agent": {"docs": {"model": "x"}}, "tools": {"bash": false}}`,
+			embedded:      `{"agent": {"docs": {"model": "m"}}, "tools": {"bash": true}}`,
+			want:          `{"agent": {"docs": {"model": "x"}}, "tools": {"bash": false}}`,
+			wantConflicts: []string{"agent.docs", "tools.bash"},
+		},
+		{
+			name:     "existing scalars win",
+			existing: `{"model": "mine"}`,
+			embedded: `{"model": "theirs"}`,
+			want:     `{"model": "mine"}`,
+		},
+		{
+			name:     "empty existing config",
+			existing: `null`,
+			embedded: `{"model": "m"}`,
+			want:     `{"model": "m"}`,
+		},
+		{
+			name:     "invalid existing config",
+			existing: `{"agent": `,
+			embedded: `{}`,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged, conflicts, err := mergeConfig([]byte(tt.existing), []byte(tt.embedded))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("mergeConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(merged, &got); err != nil {
+				t.Fatalf("merged config is invalid: %v\n%s", err, merged)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("mergeConfig() =\n%s\nwant %s", merged, tt.want)
+			}
+			if !reflect.DeepEqual(conflicts, tt.wantConflicts) {
+				t.Errorf("conflicts = %v, want %v", conflicts, tt.wantConflicts)
+			}
+		})
+	}
+}
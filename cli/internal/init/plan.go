@@ -0,0 +1,147 @@
+package init
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Action is what Initialize would do with a file
+type Action string
+
+const (
+	// ActionCreate means the file doesn't exist and would be created
+	ActionCreate Action = "create"
+	// ActionOverwrite means an existing file would be replaced (--force)
+	ActionOverwrite Action = "overwrite"
+	// ActionMerge means an existing file would be extended, keeping its content
+	ActionMerge Action = "merge"
+	// ActionKeep means an existing file would be left as it is (--merge)
+	ActionKeep Action = "keep"
+	// ActionConflict means an existing file would make Initialize fail
+	ActionConflict Action = "conflict"
+)
+
+// PlannedFile is a file Initialize would write
+type PlannedFile struct {
+	// Path is slash-separated and relative to the project root
+	Path   string
+	Action Action
+}
+
+// Plan returns what Initialize would do with each file it writes into
+// targetDir with opts, without changing anything. Files that only get
+// entries appended (.gitignore, .env.example) never conflict.
+func Plan(targetDir string, opts Options) ([]PlannedFile, error) {
+	src, err := resolveSource(opts)
+	if err != nil {
+		return nil, err
+	}
+	dests, err := sourceFileDests(src, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	exists := func(rel string) bool {
+		_, err := os.Lstat(filepath.Join(targetDir, filepath.FromSlash(rel)))
+		return err == nil
+	}
+
+	var plan []PlannedFile
+	for _, dest := range dests {
+		action := ActionCreate
+		if exists(dest) {
+			switch {
+			case opts.Force:
+				action = ActionOverwrite
+			case opts.Merge && dest == "opencode.json":
+				action = ActionMerge
+			case opts.Merge:
+				action = ActionKeep
+			default:
+				action = ActionConflict
+			}
+		}
+		plan = append(plan, PlannedFile{Path: dest, Action: action})
+	}
+
+	// Without --into, an existing .opencode is refused even if none of its
+	// files would be overwritten
+	if !opts.Into && !opts.Merge && !opts.Force && len(opts.Only) == 0 && exists(".opencode") && len(conflicts(plan)) == 0 {
+		plan = append(plan, PlannedFile{Path: ".opencode/", Action: ActionConflict})
+	}
+
+	if opts.EnvExample {
+		config, err := src.ReadConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read opencode.json: %w", err)
+		}
+		existing, err := readOptional(filepath.Join(targetDir, ".env.example"))
+		if err != nil {
+			return nil, err
+		}
+		if _, names, err := envExampleContent(existing, config); err != nil {
+			return nil, err
+		} else if len(names) > 0 {
+			plan = append(plan, appendedFile(".env.example", existing))
+		}
+	}
+	if opts.GitIgnore {
+		existing, err := readOptional(filepath.Join(targetDir, ".gitignore"))
+		if err != nil {
+			return nil, err
+		}
+		if gitignoreContent(existing) != nil {
+			plan = append(plan, appendedFile(".gitignore", existing))
+		}
+	}
+	if opts.Manifest {
+		action := ActionCreate
+		if exists(ManifestFile) {
+			action = ActionMerge
+		}
+		plan = append(plan, PlannedFile{Path: ManifestFile, Action: action})
+	}
+
+	return plan, nil
+}
+
+// appendedFile plans a file that entries are appended to
+func appendedFile(path string, existing []byte) PlannedFile {
+	if existing == nil {
+		return PlannedFile{Path: path, Action: ActionCreate}
+	}
+	return PlannedFile{Path: path, Action: ActionMerge}
+}
+
+// readOptional reads a file, returning nil if it doesn't exist
+func readOptional(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return content, err
+}
+
+// conflicts returns the paths of the conflicting files in plan
+func conflicts(plan []PlannedFile) []string {
+	var paths []string
+	for _, file := range plan {
+		if file.Action == ActionConflict {
+			paths = append(paths, file.Path)
+		}
+	}
+	return paths
+}
+
+// ConflictError is returned by Initialize when files it would create
+// already exist
+type ConflictError struct {
+	Paths []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("%d file(s) already exist (use --force to overwrite or --merge to keep them):\n  %s",
+		len(e.Paths), strings.Join(e.Paths, "\n  "))
+}
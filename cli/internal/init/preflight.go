@@ -0,0 +1,49 @@
+package init
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// CheckWritable checks that files can be created in dir by creating and
+// removing a temporary file there. If dir doesn't exist yet, its nearest
+// existing parent is checked instead, since dir would be created in it.
+func CheckWritable(dir string) error {
+	if dir == "" {
+		dir = "."
+	}
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return fmt.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("cannot access %s: %w", existing, err)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return fmt.Errorf("cannot access %s: %w", dir, err)
+		}
+		existing = parent
+	}
+
+	f, err := os.CreateTemp(existing, ".fifi-write-check-*")
+	if err != nil {
+		if errors.Is(err, os.ErrPermission) {
+			return fmt.Errorf("%s is not writable: permission denied", existing)
+		}
+		return fmt.Errorf("%s is not writable: %w", existing, err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return nil
+}
@@ -0,0 +1,154 @@
+package init
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/dscv103/fionacode/cli/internal/assets"
+	"github.com/dscv103/fionacode/cli/internal/jsonedit"
+)
+
+// presetSource limits a Source to the agents, files and MCP servers of a
+// preset
+type presetSource struct {
+	Source
+	preset assets.Preset
+}
+
+// withPreset returns src limited to the named preset
+func withPreset(src Source, name string) (Source, error) {
+	preset, err := assets.LookupPreset(name)
+	if err != nil {
+		return nil, err
+	}
+	return presetSource{Source: src, preset: preset}, nil
+}
+
+// ReadConfig returns the source's opencode.json without the agents and MCP
+// servers the preset excludes, and without the entries of the top-level
+// tools map none of the remaining agents use; the remaining entries keep
+// their order
+func (s presetSource) ReadConfig() ([]byte, error) {
+	content, err := s.Source.ReadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	var config struct {
+		Agent map[string]json.RawMessage `json:"agent"`
+		MCP   map[string]json.RawMessage `json:"mcp"`
+		Tools map[string]json.RawMessage `json:"tools"`
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse opencode.json: %w", err)
+	}
+
+	for _, section := range []struct {
+		key      string
+		entries  map[string]json.RawMessage
+		included []string
+	}{
+		{"agent", config.Agent, s.preset.Agents},
+		{"mcp", config.MCP, s.preset.MCP},
+	} {
+		for _, name := range excluded(section.entries, section.included) {
+			content, err = jsonedit.Remove(content, section.key, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	if s.preset.Agents != nil {
+		used := referencedTools(config.Agent, s.preset.Agents, config.Tools)
+		for _, name := range excluded(config.Tools, used) {
+			content, err = jsonedit.Remove(content, "tools", name)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return content, nil
+}
+
+// referencedTools returns the keys of the top-level tools map that the
+// tools of the named agents use, directly or, for keys that are glob
+// patterns such as "github_*", by matching them
+func referencedTools(agents map[string]json.RawMessage, names []string, tools map[string]json.RawMessage) []string {
+	used := make(map[string]bool)
+	for _, name := range names {
+		var agent struct {
+			Tools map[string]json.RawMessage `json:"tools"`
+		}
+		// A malformed agent is left for validation to report
+		if json.Unmarshal(agents[name], &agent) != nil {
+			continue
+		}
+		for tool := range agent.Tools {
+			used[tool] = true
+		}
+	}
+
+	referenced := []string{}
+	for key := range tools {
+		for tool := range used {
+			if matched, err := path.Match(key, tool); key == tool || (err == nil && matched) {
+				referenced = append(referenced, key)
+				break
+			}
+		}
+	}
+	return referenced
+}
+
+func (s presetSource) PromptFiles() ([]string, error) {
+	files, err := s.Source.PromptFiles()
+	return filterFiles(files, s.preset.Prompts), err
+}
+
+func (s presetSource) ToolFiles() ([]string, error) {
+	files, err := s.Source.ToolFiles()
+	return filterFiles(files, s.preset.Tools), err
+}
+
+// excluded returns the sorted names in entries that aren't in included; a
+// nil included list excludes nothing
+func excluded(entries map[string]json.RawMessage, included []string) []string {
+	if included == nil {
+		return nil
+	}
+	keep := make(map[string]bool, len(included))
+	for _, name := range included {
+		keep[name] = true
+	}
+
+	var names []string
+	for name := range entries {
+		if !keep[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// filterFiles keeps the files whose base name is in included; a nil
+// included list keeps every file
+func filterFiles(files []string, included []string) []string {
+	if included == nil {
+		return files
+	}
+	keep := make(map[string]bool, len(included))
+	for _, name := range included {
+		keep[name] = true
+	}
+
+	var filtered []string
+	for _, file := range files {
+		if keep[path.Base(file)] {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,133 @@
+package init
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+)
+
+// RepairResult describes what Repair changed
+type RepairResult struct {
+	// Created lists the directories created, slash-separated and relative
+	// to the project root
+	Created []string
+	// Restored lists the prompt files copied from the source
+	Restored []string
+}
+
+// Changed reports whether Repair changed anything
+func (r *RepairResult) Changed() bool {
+	return len(r.Created) > 0 || len(r.Restored) > 0
+}
+
+// Repair fixes the drift between a project and its opencode.json that is
+// unambiguous and safe to undo: it creates a missing .opencode,
+// .opencode/prompts or .opencode/tool directory, and copies prompt files
+// agents reference but that don't exist from src (the embedded assets when
+// nil), if src has a file at the same path. Existing files are never
+// changed; every other problem is left for the user.
+func Repair(targetDir string, src Source) (*RepairResult, error) {
+	if src == nil {
+		src = EmbeddedSource()
+	}
+
+	config, err := validate.LoadConfig(targetDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RepairResult{}
+	for _, dir := range []string{".opencode", ".opencode/prompts", ".opencode/tool"} {
+		created, err := repairDir(targetDir, dir)
+		if err != nil {
+			return result, err
+		}
+		if created {
+			result.Created = append(result.Created, dir)
+		}
+	}
+
+	sourcePrompts, err := src.PromptFiles()
+	if err != nil {
+		return result, fmt.Errorf("failed to list prompt files: %w", err)
+	}
+	available := make(map[string]bool, len(sourcePrompts))
+	for _, file := range sourcePrompts {
+		available[file] = true
+	}
+
+	referenced := make(map[string]bool)
+	for _, agent := range config.Agent {
+		if agent.Prompt != "" {
+			referenced[path.Clean(filepath.ToSlash(agent.Prompt))] = true
+		}
+	}
+	prompts := make([]string, 0, len(referenced))
+	for prompt := range referenced {
+		prompts = append(prompts, prompt)
+	}
+	sort.Strings(prompts)
+
+	for _, prompt := range prompts {
+		if !available[prompt] {
+			continue
+		}
+		dest := filepath.Join(targetDir, filepath.FromSlash(prompt))
+		if _, err := os.Lstat(dest); !os.IsNotExist(err) {
+			continue
+		}
+		if err := restoreFile(src, prompt, dest); err != nil {
+			return result, err
+		}
+		result.Restored = append(result.Restored, prompt)
+	}
+
+	return result, nil
+}
+
+// repairDir creates the project-relative directory dir if nothing exists
+// at its path, reporting whether it did
+func repairDir(targetDir, dir string) (bool, error) {
+	full := filepath.Join(targetDir, filepath.FromSlash(dir))
+	if _, err := os.Lstat(full); !os.IsNotExist(err) {
+		// Already there, or something Repair shouldn't touch
+		return false, nil
+	}
+	if err := os.Mkdir(full, 0755); err != nil {
+		return false, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	return true, nil
+}
+
+// restoreFile copies a source file to dest, which must not exist yet
+func restoreFile(src Source, file, dest string) error {
+	mode, err := src.FileMode(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	in, err := src.Open(file)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", file, err)
+	}
+	defer in.Close()
+
+	logger.Debug("restoring file", "file", file, "mode", mode.String())
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dest)
+		return fmt.Errorf("failed to write %s: %w", file, err)
+	}
+	return out.Close()
+}
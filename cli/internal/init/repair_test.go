@@ -0,0 +1,109 @@
+package init
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestRepair(t *testing.T) {
+	const config = `{
+  "agent": {
+    "docs": {"prompt": ".opencode/prompts/docs.txt"},
+    "review": {"prompt": "./.opencode/prompts/review.txt"},
+    "mine": {"prompt": ".opencode/prompts/mine.txt"}
+  }
+}`
+
+	tests := []struct {
+		name string
+		// files are written to the project before repairing it
+		files        map[string]string
+		wantCreated  []string
+		wantRestored []string
+	}{
+		{
+			name:         "empty project",
+			wantCreated:  []string{".opencode", ".opencode/prompts", ".opencode/tool"},
+			wantRestored: []string{".opencode/prompts/docs.txt", ".opencode/prompts/review.txt"},
+		},
+		{
+			name:         "existing prompts are kept",
+			files:        map[string]string{".opencode/prompts/docs.txt": "mine"},
+			wantCreated:  []string{".opencode/tool"},
+			wantRestored: []string{".opencode/prompts/review.txt"},
+		},
+		{
+			name: "nothing to repair",
+			files: map[string]string{
+				".opencode/prompts/docs.txt":   "mine",
+				".opencode/prompts/review.txt": "mine",
+				".opencode/tool/.keep":         "",
+			},
+		},
+	}
+
+	template := t.TempDir()
+	writeFiles(t, template, map[string]string{
+		"opencode.json":                config,
+		".opencode/prompts/docs.txt":   "docs",
+		".opencode/prompts/review.txt": "review",
+		".opencode/tool/lint.ts":       "lint",
+	})
+	src, err := NewDirSource(template)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFiles(t, dir, map[string]string{"opencode.json": config})
+			writeFiles(t, dir, tt.files)
+
+			result, err := Repair(dir, src)
+			if err != nil {
+				t.Fatalf("Repair() error = %v", err)
+			}
+			if !reflect.DeepEqual(result.Created, tt.wantCreated) {
+				t.Errorf("Created = %v, want %v", result.Created, tt.wantCreated)
+			}
+			if !reflect.DeepEqual(result.Restored, tt.wantRestored) {
+				t.Errorf("Restored = %v, want %v", result.Restored, tt.wantRestored)
+			}
+			if result.Changed() != (len(tt.wantCreated)+len(tt.wantRestored) > 0) {
+				t.Errorf("Changed() = %v", result.Changed())
+			}
+
+			// Restored prompts come from the template; existing ones are untouched
+			for _, prompt := range []string{"docs", "review"} {
+				want := prompt
+				if _, ok := tt.files[".opencode/prompts/"+prompt+".txt"]; ok {
+					want = "mine"
+				}
+				got, err := os.ReadFile(filepath.Join(dir, ".opencode", "prompts", prompt+".txt"))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("%s.txt = %q, want %q", prompt, got, want)
+				}
+			}
+		})
+	}
+}
+
+// writeFiles writes each slash-separated path in files under dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for rel, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
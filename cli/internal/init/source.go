@@ -0,0 +1,193 @@
+package init
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dscv103/fionacode/cli/internal/assets"
+	"github.com/dscv103/fionacode/cli/internal/validate"
+)
+
+// Source provides the files Initialize copies into a project. Paths returned
+// by PromptFiles and ToolFiles are slash-separated and relative to the
+// project root (e.g. ".opencode/prompts/docs.txt").
+type Source interface {
+	// ReadConfig returns the contents of opencode.json
+	ReadConfig() ([]byte, error)
+	// PromptFiles returns the paths of every prompt file
+	PromptFiles() ([]string, error)
+	// ToolFiles returns the paths of every tool file
+	ToolFiles() ([]string, error)
+	// ReadFile reads a path returned by PromptFiles or ToolFiles
+	ReadFile(path string) ([]byte, error)
+	// Open opens a path returned by PromptFiles or ToolFiles for streaming
+	Open(path string) (fs.File, error)
+	// FileMode returns the permissions a path returned by PromptFiles or
+	// ToolFiles should be written with
+	FileMode(path string) (fs.FileMode, error)
+}
+
+// embeddedPrefix is the directory the assets are embedded under
+const embeddedPrefix = "embedded/"
+
+// EmbeddedSource returns the Source backed by the assets embedded in fifi
+func EmbeddedSource() Source {
+	return embeddedSource{}
+}
+
+type embeddedSource struct{}
+
+func (embeddedSource) ReadConfig() ([]byte, error) {
+	return assets.GetOpencodeJSON()
+}
+
+func (embeddedSource) PromptFiles() ([]string, error) {
+	return stripEmbeddedPrefix(assets.GetPromptFiles())
+}
+
+func (embeddedSource) ToolFiles() ([]string, error) {
+	return stripEmbeddedPrefix(assets.GetToolFiles())
+}
+
+func (embeddedSource) ReadFile(path string) ([]byte, error) {
+	return assets.ReadFile(embeddedPrefix + path)
+}
+
+func (embeddedSource) Open(path string) (fs.File, error) {
+	return assets.Open(embeddedPrefix + path)
+}
+
+func (embeddedSource) FileMode(path string) (fs.FileMode, error) {
+	return assets.FileMode(embeddedPrefix + path), nil
+}
+
+// stripEmbeddedPrefix turns embedded asset paths into project-relative paths
+func stripEmbeddedPrefix(files []string, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(files))
+	for _, file := range files {
+		path, ok := strings.CutPrefix(file, embeddedPrefix)
+		if !ok {
+			return nil, fmt.Errorf("embedded file %s is outside %s", file, embeddedPrefix)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// NewDirSource returns a Source that reads opencode.json, .opencode/prompts/
+// and .opencode/tool/ from a template directory on disk
+func NewDirSource(dir string) (Source, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("template directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("template %s is not a directory", dir)
+	}
+	return dirSource{root: dir}, nil
+}
+
+type dirSource struct {
+	root string
+}
+
+// ReadConfig reads the template's opencode.json, or opencode.jsonc if only
+// that exists, with any comments removed so it can be parsed and installed
+// as opencode.json
+func (s dirSource) ReadConfig() ([]byte, error) {
+	content, err := os.ReadFile(validate.ConfigPath(s.root))
+	if err != nil {
+		return nil, err
+	}
+	return validate.RemoveComments(content), nil
+}
+
+func (s dirSource) PromptFiles() ([]string, error) {
+	return s.listFiles(".opencode/prompts")
+}
+
+func (s dirSource) ToolFiles() ([]string, error) {
+	return s.listFiles(".opencode/tool")
+}
+
+func (s dirSource) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(filepath.Join(s.root, filepath.FromSlash(path)))
+}
+
+func (s dirSource) Open(path string) (fs.File, error) {
+	return os.Open(filepath.Join(s.root, filepath.FromSlash(path)))
+}
+
+// FileMode keeps the executable bits of the template's files
+func (s dirSource) FileMode(path string) (fs.FileMode, error) {
+	info, err := os.Stat(filepath.Join(s.root, filepath.FromSlash(path)))
+	if err != nil {
+		return 0, err
+	}
+	if info.Mode()&0111 != 0 {
+		return 0755, nil
+	}
+	return 0644, nil
+}
+
+// listFiles returns every file below dir, including files in subdirectories
+func (s dirSource) listFiles(dir string) ([]string, error) {
+	return walkProjectFiles(s.root, dir)
+}
+
+// walkProjectFiles returns the slash-separated, root-relative paths of every
+// file below the root-relative directory dir, in lexical order
+func walkProjectFiles(root, dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(filepath.Join(root, filepath.FromSlash(dir)), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// checkSource verifies the source provides every component selected by opts
+// before anything is written
+func checkSource(src Source, opts Options) error {
+	if opts.includes(ComponentConfig) {
+		content, err := src.ReadConfig()
+		if err != nil {
+			return fmt.Errorf("failed to read opencode.json: %w", err)
+		}
+		if !json.Valid(validate.StripComments(content)) {
+			return fmt.Errorf("opencode.json is not valid JSON")
+		}
+	}
+	if opts.includes(ComponentPrompts) {
+		if _, err := src.PromptFiles(); err != nil {
+			return fmt.Errorf("failed to list prompt files: %w", err)
+		}
+	}
+	if opts.includes(ComponentTool) {
+		if _, err := src.ToolFiles(); err != nil {
+			return fmt.Errorf("failed to list tool files: %w", err)
+		}
+	}
+	return nil
+}
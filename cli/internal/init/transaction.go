@@ -0,0 +1,259 @@
+package init
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// transaction stages files in a temporary directory inside the target and
+// moves them into place only once every file has been written, so a failed
+// init never leaves a partial project behind.
+type transaction struct {
+	targetDir string
+	stageDir  string
+	dirs      []string
+
+	// mu guards files, which CopyFile updates from several goroutines
+	mu    sync.Mutex
+	files map[string]os.FileMode
+}
+
+// newTransaction creates a staging directory alongside the target's files
+func newTransaction(targetDir string) (*transaction, error) {
+	stageDir, err := os.MkdirTemp(targetDir, ".fifi-init-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	return &transaction{
+		targetDir: targetDir,
+		stageDir:  stageDir,
+		files:     make(map[string]os.FileMode),
+	}, nil
+}
+
+// WriteFile stages content for the project-relative path rel, which must
+// stay inside the target directory
+func (t *transaction) WriteFile(rel string, content []byte, perm os.FileMode) error {
+	return t.CopyFile(rel, bytes.NewReader(content), perm)
+}
+
+// CopyFile stages the contents of r for the project-relative path rel,
+// which must stay inside the target directory. The content is streamed, so
+// memory use doesn't grow with the file size. It is safe for concurrent use.
+func (t *transaction) CopyFile(rel string, r io.Reader, perm os.FileMode) error {
+	if !filepath.IsLocal(rel) {
+		return fmt.Errorf("refusing to write %s: path escapes %s", rel, t.targetDir)
+	}
+
+	stagePath := filepath.Join(t.stageDir, "new", rel)
+	if err := os.MkdirAll(filepath.Dir(stagePath), 0755); err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(stagePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Join(t.targetDir, rel), err)
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write %s: %w", filepath.Join(t.targetDir, rel), err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filepath.Join(t.targetDir, rel), err)
+	}
+
+	t.mu.Lock()
+	t.files[rel] = perm
+	t.mu.Unlock()
+	return nil
+}
+
+// PrepareDir creates the staging directory for the project-relative path
+// rel ahead of time, so files can then be copied into it concurrently
+func (t *transaction) PrepareDir(rel string) error {
+	if !filepath.IsLocal(rel) {
+		return fmt.Errorf("refusing to write %s: path escapes %s", rel, t.targetDir)
+	}
+	return os.MkdirAll(filepath.Dir(filepath.Join(t.stageDir, "new", rel)), 0755)
+}
+
+// MkdirAll records a project-relative directory to create on commit, even
+// if no files are staged inside it
+func (t *transaction) MkdirAll(rel string) {
+	t.dirs = append(t.dirs, rel)
+}
+
+// CommitError is returned by Commit when a staged file couldn't be moved
+// into place. Every file already updated is rolled back; files that
+// couldn't be restored to their previous state are listed so they can be
+// reported rather than silently left half-updated.
+type CommitError struct {
+	// Path is the file or directory that couldn't be written
+	Path string
+	Err  error
+	// RolledBack lists the files that had been updated and were restored
+	RolledBack []string
+	// Unrestored lists the files that couldn't be restored
+	Unrestored []string
+	// BackupDir holds the previous versions of the Unrestored files; it is
+	// only kept when some file couldn't be restored
+	BackupDir string
+}
+
+func (e *CommitError) Error() string {
+	msg := e.Err.Error()
+	if len(e.RolledBack) > 0 {
+		msg += fmt.Sprintf("; %d updated file(s) were rolled back", len(e.RolledBack))
+	}
+	if len(e.Unrestored) > 0 {
+		msg += fmt.Sprintf("; %d file(s) could not be restored: %s (previous versions are in %s)",
+			len(e.Unrestored), strings.Join(e.Unrestored, ", "), e.BackupDir)
+	}
+	return msg
+}
+
+func (e *CommitError) Unwrap() error {
+	return e.Err
+}
+
+// Commit moves every staged file into the target directory. If any move
+// fails, files already moved are reverted and replaced files restored, and
+// a *CommitError reports the outcome.
+func (t *transaction) Commit() (err error) {
+	defer func() {
+		// Keep the previous versions of files that couldn't be restored
+		var commitErr *CommitError
+		if errors.As(err, &commitErr) && len(commitErr.Unrestored) > 0 {
+			return
+		}
+		t.Abort()
+	}()
+
+	var (
+		createdDirs []string
+		moved       []string
+		replaced    = make(map[string]bool)
+		unrestored  []string
+	)
+
+	fail := func(path string, err error) error {
+		commitErr := &CommitError{Path: path, Err: err, Unrestored: unrestored}
+		for i := len(moved) - 1; i >= 0; i-- {
+			rel := moved[i]
+			dest := filepath.Join(t.targetDir, rel)
+			restoreErr := os.Remove(dest)
+			if replaced[rel] && restoreErr == nil {
+				restoreErr = os.Rename(filepath.Join(t.stageDir, "old", rel), dest)
+			}
+			if restoreErr != nil {
+				commitErr.Unrestored = append(commitErr.Unrestored, dest)
+			} else {
+				commitErr.RolledBack = append(commitErr.RolledBack, dest)
+			}
+		}
+		for i := len(createdDirs) - 1; i >= 0; i-- {
+			os.Remove(createdDirs[i])
+		}
+		if len(commitErr.Unrestored) > 0 {
+			commitErr.BackupDir = filepath.Join(t.stageDir, "old")
+		}
+		return commitErr
+	}
+
+	for _, rel := range t.dirs {
+		dirs, err := mkdirAllTracked(filepath.Join(t.targetDir, rel))
+		createdDirs = append(createdDirs, dirs...)
+		if err != nil {
+			return fail(filepath.Join(t.targetDir, rel), fmt.Errorf("failed to create %s directory: %w", rel, err))
+		}
+	}
+
+	for _, rel := range t.Files() {
+		dest := filepath.Join(t.targetDir, rel)
+
+		dirs, err := mkdirAllTracked(filepath.Dir(dest))
+		createdDirs = append(createdDirs, dirs...)
+		if err != nil {
+			return fail(dest, fmt.Errorf("failed to create directory for %s: %w", dest, err))
+		}
+
+		if _, err := os.Lstat(dest); err == nil {
+			old := filepath.Join(t.stageDir, "old", rel)
+			if err := os.MkdirAll(filepath.Dir(old), 0755); err != nil {
+				return fail(dest, err)
+			}
+			if err := os.Rename(dest, old); err != nil {
+				return fail(dest, fmt.Errorf("failed to replace %s: %w", dest, err))
+			}
+			replaced[rel] = true
+		}
+
+		if err := os.Rename(filepath.Join(t.stageDir, "new", rel), dest); err != nil {
+			err = fmt.Errorf("failed to move %s into place: %w", dest, err)
+			if replaced[rel] {
+				if restoreErr := os.Rename(filepath.Join(t.stageDir, "old", rel), dest); restoreErr != nil {
+					unrestored = append(unrestored, dest)
+				}
+			}
+			return fail(dest, err)
+		}
+		moved = append(moved, rel)
+	}
+
+	return nil
+}
+
+// Files returns the project-relative paths of every staged file, sorted
+func (t *transaction) Files() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	rels := make([]string, 0, len(t.files))
+	for rel := range t.files {
+		rels = append(rels, rel)
+	}
+	sort.Strings(rels)
+	return rels
+}
+
+// OpenStaged opens the staged content of the project-relative path rel
+func (t *transaction) OpenStaged(rel string) (*os.File, error) {
+	return os.Open(filepath.Join(t.stageDir, "new", rel))
+}
+
+// Abort discards the staging directory and everything staged in it
+func (t *transaction) Abort() {
+	os.RemoveAll(t.stageDir)
+}
+
+// mkdirAllTracked behaves like os.MkdirAll but returns the directories it
+// created, outermost first, so they can be removed on rollback
+func mkdirAllTracked(dir string) ([]string, error) {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	var created []string
+	for i := len(missing) - 1; i >= 0; i-- {
+		if err := os.Mkdir(missing[i], 0755); err != nil && !os.IsExist(err) {
+			return created, err
+		}
+		created = append(created, missing[i])
+	}
+	return created, nil
+}
@@ -0,0 +1,92 @@
+package init
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTransactionCommit(t *testing.T) {
+	tests := []struct {
+		name string
+		// blocker is a file in the target that makes committing z/new.txt fail
+		blocker        bool
+		wantA          string
+		wantRolledBack []string
+	}{
+		{name: "commit", wantA: "new"},
+		// Moves are undone in reverse: b/new.txt is removed, a.txt restored
+		{name: "roll back", blocker: true, wantA: "old", wantRolledBack: []string{"b/new.txt", "a.txt"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("old"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if tt.blocker {
+				if err := os.WriteFile(filepath.Join(dir, "z"), nil, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			tx, err := newTransaction(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Files commit in sorted order, so a.txt is replaced first
+			for rel, content := range map[string]string{"a.txt": "new", "b/new.txt": "b", "z/new.txt": "z"} {
+				if err := tx.WriteFile(rel, []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err = tx.Commit()
+			var commitErr *CommitError
+			if tt.blocker {
+				if !errors.As(err, &commitErr) {
+					t.Fatalf("Commit() error = %v, want a *CommitError", err)
+				}
+				if want := filepath.Join(dir, "z", "new.txt"); commitErr.Path != want {
+					t.Errorf("Path = %s, want %s", commitErr.Path, want)
+				}
+				var rolledBack []string
+				for _, path := range commitErr.RolledBack {
+					rel, _ := filepath.Rel(dir, path)
+					rolledBack = append(rolledBack, filepath.ToSlash(rel))
+				}
+				if !reflect.DeepEqual(rolledBack, tt.wantRolledBack) {
+					t.Errorf("RolledBack = %v, want %v", rolledBack, tt.wantRolledBack)
+				}
+				if len(commitErr.Unrestored) > 0 || commitErr.BackupDir != "" {
+					t.Errorf("Unrestored = %v, BackupDir = %q, want none", commitErr.Unrestored, commitErr.BackupDir)
+				}
+				if _, err := os.Lstat(filepath.Join(dir, "b")); !os.IsNotExist(err) {
+					t.Errorf("directory b left behind: %v", err)
+				}
+			} else if err != nil {
+				t.Fatalf("Commit() error = %v", err)
+			}
+
+			got, err := os.ReadFile(filepath.Join(dir, "a.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.wantA {
+				t.Errorf("a.txt = %q, want %q", got, tt.wantA)
+			}
+
+			// The staging directory is removed either way
+			entries, err := filepath.Glob(filepath.Join(dir, ".fifi-init-*"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) > 0 {
+				t.Errorf("staging directory left behind: %v", entries)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package init
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/dscv103/fionacode/cli/internal/validate"
+)
+
+// UpgradeOptions controls how Upgrade refreshes a project
+type UpgradeOptions struct {
+	// Overwrite replaces prompt and tool files that differ from the source;
+	// by default they're treated as local customizations and kept
+	Overwrite bool
+	// MergeConfig adds missing source entries to opencode.json; by default
+	// opencode.json is left alone
+	MergeConfig bool
+	// NoBackup skips backing up files that Overwrite is about to replace
+	NoBackup bool
+	// Source provides the files to upgrade to; nil means the embedded assets
+	Source Source
+}
+
+// UpgradeResult describes what Upgrade did. Paths are slash-separated and
+// relative to the project root.
+type UpgradeResult struct {
+	// Added lists files the project was missing
+	Added []string
+	// Updated lists modified files that were overwritten
+	Updated []string
+	// Skipped lists modified files that were kept
+	Skipped []string
+	// ConfigMerged is true if opencode.json was merged
+	ConfigMerged bool
+	// Conflicts lists config entries left untouched during the merge
+	Conflicts []string
+	// BackupDir is the directory holding copies of overwritten files, or ""
+	BackupDir string
+}
+
+// Upgrade brings the prompt and tool files of an initialized project in line
+// with the source. Files the project is missing are added; files that differ
+// are only replaced with Overwrite. Like Initialize, all writes are staged
+// and committed together.
+func Upgrade(targetDir string, opts UpgradeOptions) (*UpgradeResult, error) {
+	if targetDir == "" {
+		var err error
+		targetDir, err = os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
+		}
+	}
+
+	opencodeJSONPath := validate.ConfigPath(targetDir)
+	if _, err := os.Stat(opencodeJSONPath); err != nil {
+		return nil, fmt.Errorf("opencode.json not found in %s; run 'fifi init' first", targetDir)
+	}
+
+	src := opts.Source
+	if src == nil {
+		src = EmbeddedSource()
+	}
+
+	diffs, err := Diff(targetDir, src)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &UpgradeResult{}
+	var writes []string
+	for _, diff := range diffs {
+		if diff.Path == "opencode.json" {
+			continue
+		}
+		switch diff.Status {
+		case StatusAdded:
+			result.Added = append(result.Added, diff.Path)
+			writes = append(writes, diff.Path)
+		case StatusModified:
+			if opts.Overwrite {
+				result.Updated = append(result.Updated, diff.Path)
+				writes = append(writes, diff.Path)
+			} else {
+				result.Skipped = append(result.Skipped, diff.Path)
+			}
+		}
+	}
+
+	if len(result.Updated) > 0 && !opts.NoBackup {
+		dests := make([]string, len(result.Updated))
+		for i, path := range result.Updated {
+			dests[i] = filepath.FromSlash(path)
+		}
+		backupDir, err := backupFiles(targetDir, dests, time.Now())
+		if err != nil {
+			return nil, fmt.Errorf("failed to back up existing files: %w", err)
+		}
+		result.BackupDir = backupDir
+	}
+
+	tx, err := newTransaction(targetDir)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Abort()
+
+	if opts.MergeConfig {
+		conflicts, err := stageMergedOpencodeJSON(tx, src, opencodeJSONPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to merge opencode.json: %w", err)
+		}
+		result.ConfigMerged = true
+		result.Conflicts = conflicts
+	}
+
+	if _, err := copyFiles(tx, src, writes, false); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,375 @@
+// Package jsonedit makes surgical edits to JSON documents: members are
+// added, replaced or removed in place, so the key order, indentation and
+// everything else about the rest of the document stay exactly as they were.
+package jsonedit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned when a path doesn't exist in the document
+var ErrNotFound = errors.New("not found")
+
+// member is a key/value pair of an object, as byte offsets into the document.
+// leadStart is where the whitespace and comments before the key begin, just
+// after the preceding '{' or ','; comments belong to the member they precede.
+type member struct {
+	key        string
+	leadStart  int
+	keyStart   int
+	valueStart int
+	valueEnd   int
+}
+
+// Remove deletes the member at path (a sequence of object keys) along with
+// its separating comma
+func Remove(doc []byte, path ...string) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	start, end, err := locate(doc, path[:len(path)-1])
+	if err != nil {
+		return nil, err
+	}
+	members, err := objectMembers(doc, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	key := path[len(path)-1]
+	for i, m := range members {
+		if m.key != key {
+			continue
+		}
+		switch {
+		case i+1 < len(members):
+			// Remove this member and its comments, up to the next member's
+			return splice(doc, m.leadStart, members[i+1].leadStart, nil), nil
+		case i > 0:
+			// Last member: remove from the end of the previous value, taking
+			// the comma with it
+			return splice(doc, members[i-1].valueEnd, m.valueEnd, nil), nil
+		default:
+			// Only member: leave an empty object
+			return splice(doc, start+1, end-1, nil), nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %w", strings.Join(path, "."), ErrNotFound)
+}
+
+// Set replaces the value of the member at path, or appends the member to
+// its object if it doesn't exist yet, creating missing parent objects. New
+// values are indented to match the surrounding members.
+func Set(doc []byte, value interface{}, path ...string) ([]byte, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+
+	parentPath := path[:len(path)-1]
+	start, end, err := locate(doc, parentPath)
+	if errors.Is(err, ErrNotFound) && len(parentPath) > 0 {
+		// Create the parent object first, then set the member inside it
+		doc, err = Set(doc, map[string]interface{}{}, parentPath...)
+		if err != nil {
+			return nil, err
+		}
+		start, end, err = locate(doc, parentPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := objectMembers(doc, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	parentIndent := lineIndent(doc, start)
+	memberIndent := parentIndent + documentIndent(doc)
+	if len(members) > 0 {
+		memberIndent = lineIndent(doc, members[0].keyStart)
+	}
+
+	encoded, err := marshal(value, memberIndent, indentUnit(parentIndent, memberIndent))
+	if err != nil {
+		return nil, err
+	}
+
+	key := path[len(path)-1]
+	for _, m := range members {
+		if m.key == key {
+			return splice(doc, m.valueStart, m.valueEnd, encoded), nil
+		}
+	}
+
+	encodedKey, err := marshal(key, "", "")
+	if err != nil {
+		return nil, err
+	}
+	entry := append(append(encodedKey, ": "...), encoded...)
+
+	if len(members) == 0 {
+		insert := "\n" + memberIndent + string(entry) + "\n" + parentIndent
+		return splice(doc, start+1, end-1, []byte(insert)), nil
+	}
+	last := members[len(members)-1]
+	insert := ",\n" + memberIndent + string(entry)
+	return splice(doc, last.valueEnd, last.valueEnd, []byte(insert)), nil
+}
+
+// marshal encodes v like json.MarshalIndent without escaping HTML
+// characters such as < and >, which are common in prompts and commands
+func marshal(v interface{}, prefix, indent string) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent(prefix, indent)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// splice replaces doc[start:end] with insert
+func splice(doc []byte, start, end int, insert []byte) []byte {
+	out := make([]byte, 0, len(doc)-(end-start)+len(insert))
+	out = append(out, doc[:start]...)
+	out = append(out, insert...)
+	return append(out, doc[end:]...)
+}
+
+// lineIndent returns the whitespace at the start of the line containing
+// offset
+func lineIndent(doc []byte, offset int) string {
+	lineStart := bytes.LastIndexByte(doc[:offset], '\n') + 1
+	end := lineStart
+	for end < offset && (doc[end] == ' ' || doc[end] == '\t') {
+		end++
+	}
+	return string(doc[lineStart:end])
+}
+
+// documentIndent returns the indentation of the top-level members, which
+// new members of an empty object are indented by
+func documentIndent(doc []byte) string {
+	start, end, err := locate(doc, nil)
+	if err != nil {
+		return "  "
+	}
+	members, err := objectMembers(doc, start, end)
+	if err != nil || len(members) == 0 {
+		return "  "
+	}
+	if indent := lineIndent(doc, members[0].keyStart); indent != "" {
+		return indent
+	}
+	return "  "
+}
+
+// indentUnit returns the indentation added per nesting level
+func indentUnit(parentIndent, memberIndent string) string {
+	if unit := strings.TrimPrefix(memberIndent, parentIndent); unit != "" && unit != memberIndent {
+		return unit
+	}
+	return "  "
+}
+
+// locate returns the byte range of the object at path, which must exist
+func locate(doc []byte, path []string) (start, end int, err error) {
+	s := &scanner{doc: doc}
+	s.skipSpace()
+	start = s.pos
+	if end, err = s.skipValue(); err != nil {
+		return 0, 0, err
+	}
+
+	for i, key := range path {
+		members, err := objectMembers(doc, start, end)
+		if err != nil {
+			return 0, 0, fmt.Errorf("%s: %w", strings.Join(path[:i], "."), err)
+		}
+		found := false
+		for _, m := range members {
+			if m.key == key {
+				start, end, found = m.valueStart, m.valueEnd, true
+				break
+			}
+		}
+		if !found {
+			return 0, 0, fmt.Errorf("%s: %w", strings.Join(path[:i+1], "."), ErrNotFound)
+		}
+	}
+
+	if doc[start] != '{' {
+		return 0, 0, fmt.Errorf("%s is not an object", strings.Join(path, "."))
+	}
+	return start, end, nil
+}
+
+// objectMembers lists the members of the object spanning doc[start:end]
+func objectMembers(doc []byte, start, end int) ([]member, error) {
+	if doc[start] != '{' {
+		return nil, fmt.Errorf("not an object")
+	}
+
+	s := &scanner{doc: doc[:end], pos: start + 1}
+	var members []member
+	for {
+		leadStart := s.pos
+		s.skipSpace()
+		if s.peek() == '}' {
+			return members, nil
+		}
+
+		m := member{leadStart: leadStart}
+		m.keyStart = s.pos
+		keyEnd, err := s.skipString()
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(doc[m.keyStart:keyEnd], &m.key); err != nil {
+			return nil, err
+		}
+
+		s.skipSpace()
+		if err := s.expect(':'); err != nil {
+			return nil, err
+		}
+		s.skipSpace()
+		m.valueStart = s.pos
+		if m.valueEnd, err = s.skipValue(); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+
+		s.skipSpace()
+		if s.peek() == ',' {
+			s.pos++
+			continue
+		}
+		if s.peek() != '}' {
+			return nil, s.errorf("expected ',' or '}'")
+		}
+	}
+}
+
+// scanner walks a JSON document just far enough to find value boundaries
+type scanner struct {
+	doc []byte
+	pos int
+}
+
+func (s *scanner) peek() byte {
+	if s.pos >= len(s.doc) {
+		return 0
+	}
+	return s.doc[s.pos]
+}
+
+func (s *scanner) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid JSON at offset %d: %s", s.pos, fmt.Sprintf(format, args...))
+}
+
+func (s *scanner) expect(c byte) error {
+	if s.peek() != c {
+		return s.errorf("expected %q", c)
+	}
+	s.pos++
+	return nil
+}
+
+// skipSpace skips whitespace and any // or /* */ comments, so documents
+// with comments can be edited without losing them
+func (s *scanner) skipSpace() {
+	for s.pos < len(s.doc) {
+		switch {
+		case bytes.ContainsRune([]byte(" \t\n\r"), rune(s.doc[s.pos])):
+			s.pos++
+		case bytes.HasPrefix(s.doc[s.pos:], []byte("//")):
+			if end := bytes.IndexByte(s.doc[s.pos:], '\n'); end >= 0 {
+				s.pos += end
+			} else {
+				s.pos = len(s.doc)
+			}
+		case bytes.HasPrefix(s.doc[s.pos:], []byte("/*")):
+			if end := bytes.Index(s.doc[s.pos+2:], []byte("*/")); end >= 0 {
+				s.pos += end + 4
+			} else {
+				s.pos = len(s.doc)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// skipString skips the string starting at pos and returns its end
+func (s *scanner) skipString() (int, error) {
+	if err := s.expect('"'); err != nil {
+		return 0, err
+	}
+	for s.pos < len(s.doc) {
+		switch s.doc[s.pos] {
+		case '\\':
+			s.pos += 2
+		case '"':
+			s.pos++
+			return s.pos, nil
+		default:
+			s.pos++
+		}
+	}
+	return 0, s.errorf("unterminated string")
+}
+
+// skipValue skips the value starting at pos and returns its end
+func (s *scanner) skipValue() (int, error) {
+	switch s.peek() {
+	case '"':
+		return s.skipString()
+	case '{', '[':
+		depth := 0
+		for s.pos < len(s.doc) {
+			switch s.doc[s.pos] {
+			case '"':
+				if _, err := s.skipString(); err != nil {
+					return 0, err
+				}
+				continue
+			case '/':
+				// A comment; braces inside it don't count
+				start := s.pos
+				if s.skipSpace(); s.pos > start {
+					continue
+				}
+			case '{', '[':
+				depth++
+			case '}', ']':
+				depth--
+				if depth == 0 {
+					s.pos++
+					return s.pos, nil
+				}
+			}
+			s.pos++
+		}
+		return 0, s.errorf("unterminated object or array")
+	case 0:
+		return 0, s.errorf("unexpected end of input")
+	default:
+		// Numbers, true, false and null run until the next delimiter
+		start := s.pos
+		for s.pos < len(s.doc) && !strings.ContainsRune(",}]/ \t\r\n", rune(s.doc[s.pos])) {
+			s.pos++
+		}
+		if s.pos == start {
+			return 0, s.errorf("expected a value")
+		}
+		return s.pos, nil
+	}
+}
@@ -0,0 +1,187 @@
+package jsonedit
+
+import (
+	"errors"
+	"testing"
+)
+
+// doc has its keys out of order, four-space indentation and a comment, all
+// of which an edit must leave alone
+const doc = `{
+    "theme": "dark",
+    // tools the agents may use
+    "tools": {
+        "write": false,
+        "bash": true
+    },
+    "agent": {}
+}
+`
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		path  []string
+		want  string
+	}{
+		{
+			name:  "replace a value in place",
+			value: "light",
+			path:  []string{"theme"},
+			want: `{
+    "theme": "light",
+    // tools the agents may use
+    "tools": {
+        "write": false,
+        "bash": true
+    },
+    "agent": {}
+}
+`,
+		},
+		{
+			name:  "append a member after the last one",
+			value: true,
+			path:  []string{"tools", "edit"},
+			want: `{
+    "theme": "dark",
+    // tools the agents may use
+    "tools": {
+        "write": false,
+        "bash": true,
+        "edit": true
+    },
+    "agent": {}
+}
+`,
+		},
+		{
+			name:  "set a member of an empty object",
+			value: map[string]interface{}{"model": "m"},
+			path:  []string{"agent", "docs"},
+			want: `{
+    "theme": "dark",
+    // tools the agents may use
+    "tools": {
+        "write": false,
+        "bash": true
+    },
+    "agent": {
+        "docs": {
+            "model": "m"
+        }
+    }
+}
+`,
+		},
+		{
+			name:  "create missing parents",
+			value: []string{"echo", "<hi>"},
+			path:  []string{"mcp", "echo", "command"},
+			want: `{
+    "theme": "dark",
+    // tools the agents may use
+    "tools": {
+        "write": false,
+        "bash": true
+    },
+    "agent": {},
+    "mcp": {
+        "echo": {
+            "command": [
+                "echo",
+                "<hi>"
+            ]
+        }
+    }
+}
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Set([]byte(doc), tt.value, tt.path...)
+			if err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Set() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRemove(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    []string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "first member",
+			path: []string{"theme"},
+			want: `{
+    // tools the agents may use
+    "tools": {
+        "write": false,
+        "bash": true
+    },
+    "agent": {}
+}
+`,
+		},
+		{
+			name: "last member",
+			path: []string{"tools", "bash"},
+			want: `{
+    "theme": "dark",
+    // tools the agents may use
+    "tools": {
+        "write": false
+    },
+    "agent": {}
+}
+`,
+		},
+		{
+			name: "member with a comment before it",
+			path: []string{"tools"},
+			want: `{
+    "theme": "dark",
+    "agent": {}
+}
+`,
+		},
+		{
+			name:    "missing member",
+			path:    []string{"tools", "edit"},
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "missing parent",
+			path:    []string{"mcp", "echo"},
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Remove([]byte(doc), tt.path...)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Remove() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Remove() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Remove() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// minTemperature and maxTemperature bound what model backends accept
+	minTemperature = 0.0
+	maxTemperature = 2.0
+	// highTemperature is where output gets noticeably erratic for coding agents
+	highTemperature = 1.0
+)
+
+// checkTemperature flags temperatures outside [0.0, 2.0] as errors and
+// warns about temperatures above 1.0
+func checkTemperature(issues *issueList, name string, agent Agent) {
+	switch {
+	case agent.Temperature < minTemperature || agent.Temperature > maxTemperature:
+		issues.agentIssue(SeverityError, CategoryTemperature, name, "temperature",
+			"agent %s has temperature %g outside the allowed range [%.1f, %.1f]", name, agent.Temperature, minTemperature, maxTemperature)
+	case agent.Temperature > highTemperature:
+		issues.agentIssue(SeverityWarning, CategoryTemperature, name, "temperature",
+			"agent %s has a high temperature %g (above %.1f)", name, agent.Temperature, highTemperature)
+	}
+}
+
+// agentTypes are the values OpenCode accepts for an agent's type and mode.
+// Extend this list when OpenCode adds one.
+var agentTypes = []string{"primary", "subagent", "all"}
+
+// checkAgentType warns when an agent's type or mode isn't one of
+// agentTypes. Unknown values are warnings rather than errors so a newer
+// OpenCode type doesn't fail validation.
+func checkAgentType(issues *issueList, name string, agent Agent) {
+	for _, field := range []struct{ name, value string }{{"type", agent.Type}, {"mode", agent.Mode}} {
+		if field.value == "" || slices.Contains(agentTypes, field.value) {
+			continue
+		}
+		issues.agentIssue(SeverityWarning, CategoryAgentType, name, field.name,
+			"agent %s has unknown %s %q (expected one of: %s)", name, field.name, field.value, strings.Join(agentTypes, ", "))
+	}
+}
+
+// builtinTools are the tools OpenCode provides without any configuration
+var builtinTools = map[string]bool{
+	"bash":      true,
+	"edit":      true,
+	"glob":      true,
+	"grep":      true,
+	"list":      true,
+	"lsp":       true,
+	"patch":     true,
+	"read":      true,
+	"search":    true,
+	"skill":     true,
+	"task":      true,
+	"todoread":  true,
+	"todowrite": true,
+	"webfetch":  true,
+	"write":     true,
+}
+
+// ToolNames returns the names of the tools the agent references, sorted
+func (a Agent) ToolNames() []string {
+	return agentToolNames(a)
+}
+
+// agentToolNames returns the tool names an agent references, sorted. A
+// malformed tools field yields no names; checkToolReferences reports it.
+func agentToolNames(agent Agent) []string {
+	tools, err := NormalizeAgentTools(agent)
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(tools))
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NormalizeAgentTools returns the agent's tools as a map of tool name to
+// whether it is enabled. The tools field may be a list of names, which are
+// all enabled, or a map of name to true/false; anything else is an error.
+func NormalizeAgentTools(agent Agent) (map[string]bool, error) {
+	normalized := make(map[string]bool)
+	switch tools := agent.Tools.(type) {
+	case nil:
+	case []interface{}:
+		for i, tool := range tools {
+			name, ok := tool.(string)
+			if !ok {
+				return nil, fmt.Errorf("tools[%d] is %s, expected a tool name", i, jsonTypeName(tool))
+			}
+			normalized[name] = true
+		}
+	case map[string]interface{}:
+		for name, value := range tools {
+			enabled, ok := value.(bool)
+			if !ok {
+				return nil, fmt.Errorf("tools.%s is %s, expected true or false", name, jsonTypeName(value))
+			}
+			normalized[name] = enabled
+		}
+	default:
+		return nil, fmt.Errorf("tools is %s, expected a list of tool names or a map of tool name to true/false", jsonTypeName(tools))
+	}
+	return normalized, nil
+}
+
+// jsonTypeName describes the JSON type of a value decoded into interface{}
+func jsonTypeName(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "a boolean"
+	case float64, json.Number:
+		return "a number"
+	case string:
+		return "a string"
+	case []interface{}:
+		return "an array"
+	case map[string]interface{}:
+		return "an object"
+	default:
+		return fmt.Sprintf("a %T", v)
+	}
+}
+
+// customToolNames returns the names of the tools defined in toolDir and its
+// subdirectories, i.e. the file names without extension
+func customToolNames(toolDir string) map[string]bool {
+	names := make(map[string]bool)
+	filepath.WalkDir(toolDir, func(_ string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			names[strings.TrimSuffix(d.Name(), filepath.Ext(d.Name()))] = true
+		}
+		return nil
+	})
+	return names
+}
+
+// toolExists reports whether name is a built-in tool, is configured in the
+// top-level tools map (whose keys may be glob patterns such as "github_*"),
+// or is backed by a file in the tool directory
+func toolExists(name string, config OpencodeConfig, customTools map[string]bool) bool {
+	if builtinTools[name] || customTools[name] {
+		return true
+	}
+	for pattern := range config.Tools {
+		if pattern == name {
+			return true
+		}
+		if matched, err := path.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	// Patterns can't be resolved to a single tool, so they aren't checked
+	return strings.ContainsAny(name, "*?[")
+}
+
+// checkToolReferences reports a malformed tools field and every tool an agent
+// references that doesn't exist
+func checkToolReferences(issues *issueList, name string, agent Agent, config OpencodeConfig, customTools map[string]bool) {
+	if _, err := NormalizeAgentTools(agent); err != nil {
+		issues.agentIssue(SeverityError, CategoryToolsFormat, name, "tools", "agent %s: %v", name, err)
+		return
+	}
+	for _, tool := range agentToolNames(agent) {
+		if !toolExists(tool, config, customTools) {
+			issues.agentIssue(SeverityError, CategoryUnknownTool, name, "tools",
+				"agent %s references unknown tool %q", name, tool)
+		}
+	}
+}
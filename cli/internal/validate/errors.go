@@ -0,0 +1,24 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrConfigNotFound is returned, wrapped, when the target directory has no
+// opencode.json
+var ErrConfigNotFound = errors.New("opencode.json not found")
+
+// ParseError is returned when opencode.json exists but isn't valid JSON or
+// doesn't have the expected structure
+type ParseError struct {
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("failed to parse opencode.json: %v", e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,41 @@
+package validate
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// skippedDirs are never searched for projects: dependencies and VCS data
+var skippedDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// FindProjects returns every directory below root (including root itself)
+// that contains an opencode.json, in lexical order. Hidden directories such
+// as .git and fifi's backups, node_modules and vendor are skipped.
+func FindProjects(root string) ([]string, error) {
+	var projects []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root && (strings.HasPrefix(d.Name(), ".") || skippedDirs[d.Name()]) {
+			return filepath.SkipDir
+		}
+
+		if info, err := os.Stat(ConfigPath(path)); err == nil && !info.IsDir() {
+			projects = append(projects, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
@@ -0,0 +1,156 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Severity is how serious a validation issue is
+type Severity string
+
+const (
+	// SeverityError makes the configuration invalid
+	SeverityError Severity = "error"
+	// SeverityWarning is reported but doesn't make the configuration invalid
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a single validation finding
+type Issue struct {
+	Severity Severity `json:"severity"`
+	Category Category `json:"category"`
+	Message  string   `json:"message"`
+	// Agent is the agent the issue concerns, if any
+	Agent string `json:"agent,omitempty"`
+	// Field is the config field the issue concerns, if any
+	Field string `json:"field,omitempty"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+}
+
+// HasErrors reports whether any of the issues is an error
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Category identifies a class of validation finding that can be suppressed
+type Category string
+
+const (
+	// CategoryNoAgents is reported when opencode.json defines no agents
+	CategoryNoAgents Category = "no-agents"
+	// CategoryMissingDirectory is reported when .opencode, prompts or tool is
+	// missing or isn't a directory
+	CategoryMissingDirectory Category = "missing-directory"
+	// CategoryEmptyDirectory is reported when the prompts or tool directory contains no files
+	CategoryEmptyDirectory Category = "empty-directory"
+	// CategoryMissingPrompt is reported when an agent's prompt file doesn't exist
+	CategoryMissingPrompt Category = "missing-prompt"
+	// CategoryPromptPath is reported when an agent's prompt path is absolute
+	// or leads outside the project directory
+	CategoryPromptPath Category = "prompt-path"
+	// CategoryAgentType is reported when an agent's type or mode isn't a known value
+	CategoryAgentType Category = "agent-type"
+	// CategoryTemperature is reported when an agent's temperature is out of range
+	CategoryTemperature Category = "temperature"
+	// CategoryUnknownTool is reported when an agent references a tool that doesn't exist
+	CategoryUnknownTool Category = "unknown-tool"
+	// CategoryToolsFormat is reported when an agent's tools field is neither
+	// a list of names nor a map of name to true/false
+	CategoryToolsFormat Category = "tools-format"
+	// CategoryMCPServer is reported when an MCP server definition is incomplete or invalid
+	CategoryMCPServer Category = "mcp-server"
+	// CategoryOrphanedPrompt is reported for prompt files no agent references
+	CategoryOrphanedPrompt Category = "orphaned-prompt"
+	// CategoryEmptyPrompt is reported when an agent's prompt file has no content
+	CategoryEmptyPrompt Category = "empty-prompt"
+	// CategoryBinaryPrompt is reported when an agent's prompt file isn't UTF-8 text
+	CategoryBinaryPrompt Category = "binary-prompt"
+	// CategorySharedPrompt is reported when several agents reference the same prompt file
+	CategorySharedPrompt Category = "shared-prompt"
+	// CategorySchema is reported when opencode.json doesn't match its JSON Schema
+	CategorySchema Category = "schema"
+	// CategoryUnknownKey is reported for top-level opencode.json keys nothing understands
+	CategoryUnknownKey Category = "unknown-key"
+	// CategoryMCPEnv is reported when an MCP server references an unset environment variable
+	CategoryMCPEnv Category = "mcp-env"
+	// CategoryToolSyntax is reported for tool files that look empty, truncated or corrupted
+	CategoryToolSyntax Category = "tool-syntax"
+)
+
+// Categories returns every category that can be passed to Options.Ignore
+func Categories() []Category {
+	return []Category{
+		CategorySchema,
+		CategoryUnknownKey,
+		CategoryNoAgents,
+		CategoryMissingDirectory,
+		CategoryEmptyDirectory,
+		CategoryMissingPrompt,
+		CategoryPromptPath,
+		CategoryAgentType,
+		CategoryTemperature,
+		CategoryUnknownTool,
+		CategoryToolsFormat,
+		CategoryMCPServer,
+		CategoryMCPEnv,
+		CategoryOrphanedPrompt,
+		CategoryEmptyPrompt,
+		CategoryBinaryPrompt,
+		CategorySharedPrompt,
+		CategoryToolSyntax,
+	}
+}
+
+// ParseCategory converts a category name into a Category
+func ParseCategory(name string) (Category, error) {
+	for _, c := range Categories() {
+		if string(c) == name {
+			return c, nil
+		}
+	}
+
+	names := make([]string, 0, len(Categories()))
+	for _, c := range Categories() {
+		names = append(names, string(c))
+	}
+	return "", fmt.Errorf("unknown category %q (valid: %s)", name, strings.Join(names, ", "))
+}
+
+// issueList collects issues, dropping categories the options ignore
+type issueList struct {
+	opts   Options
+	issues []Issue
+}
+
+func (l *issueList) add(issue Issue) {
+	if l.opts.ignored(issue.Category) {
+		return
+	}
+	l.issues = append(l.issues, issue)
+}
+
+func (l *issueList) agentIssue(severity Severity, category Category, agent, field, format string, args ...interface{}) {
+	l.add(Issue{
+		Severity: severity,
+		Category: category,
+		Message:  fmt.Sprintf(format, args...),
+		Agent:    agent,
+		Field:    field,
+	})
+}
+
+func (l *issueList) errorf(category Category, format string, args ...interface{}) {
+	l.add(Issue{Severity: SeverityError, Category: category, Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *issueList) warnf(category Category, format string, args ...interface{}) {
+	l.add(Issue{Severity: SeverityWarning, Category: category, Message: fmt.Sprintf(format, args...)})
+}
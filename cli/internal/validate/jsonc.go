@@ -0,0 +1,91 @@
+package validate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+)
+
+// ConfigPath returns the path of the project's OpenCode config in
+// targetDir: opencode.json, or opencode.jsonc if only that exists
+func ConfigPath(targetDir string) string {
+	path := filepath.Join(targetDir, "opencode.json")
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		jsonc := filepath.Join(targetDir, "opencode.jsonc")
+		if _, err := os.Stat(jsonc); err == nil {
+			return jsonc
+		}
+	}
+	return path
+}
+
+// readConfig reads the config file at path with any comments removed
+func readConfig(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return StripComments(content), nil
+}
+
+// StripComments blanks out // and /* */ comments outside of strings so
+// JSON with comments can be unmarshaled. Comments are replaced with spaces
+// and newlines are kept, so offsets and line numbers don't change.
+func StripComments(content []byte) []byte {
+	out := make([]byte, len(content))
+	copy(out, content)
+
+	inString := false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case inString:
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			out[i], out[i+1] = ' ', ' '
+			for i += 2; i < len(out); i++ {
+				if out[i] == '*' && i+1 < len(out) && out[i+1] == '/' {
+					out[i], out[i+1] = ' ', ' '
+					i++
+					break
+				}
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+		}
+	}
+	return out
+}
+
+// RemoveComments removes // and /* */ comments outside of strings, for
+// writing a commented config out as plain JSON. Unlike StripComments it
+// drops lines that only held a comment and the whitespace comments leave at
+// the end of a line, so offsets change.
+func RemoveComments(content []byte) []byte {
+	original := bytes.Split(content, []byte("\n"))
+	stripped := bytes.Split(StripComments(content), []byte("\n"))
+
+	var out [][]byte
+	for i, line := range stripped {
+		trimmed := bytes.TrimRight(line, " \t")
+		if len(bytes.TrimSpace(trimmed)) == 0 && len(bytes.TrimSpace(original[i])) > 0 {
+			continue
+		}
+		if len(trimmed) < len(line) && !bytes.Equal(line, original[i]) {
+			line = trimmed
+		}
+		out = append(out, line)
+	}
+	return bytes.Join(out, []byte("\n"))
+}
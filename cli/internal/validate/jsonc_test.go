@@ -0,0 +1,45 @@
+package validate
+
+import "testing"
+
+func TestRemoveComments(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "no comments",
+			in:   "{\n  \"a\": 1\n}\n",
+			want: "{\n  \"a\": 1\n}\n",
+		},
+		{
+			name: "line comments",
+			in:   "// header\n{\n  \"a\": 1, // trailing\n  // own line\n  \"b\": 2\n}\n",
+			want: "{\n  \"a\": 1,\n  \"b\": 2\n}\n",
+		},
+		{
+			name: "block comment over several lines",
+			in:   "{\n  /* first\n     second */\n  \"a\": 1\n}",
+			want: "{\n  \"a\": 1\n}",
+		},
+		{
+			name: "comment markers in strings",
+			in:   "{\n  \"url\": \"https://example.com/*x*/\" // link\n}",
+			want: "{\n  \"url\": \"https://example.com/*x*/\"\n}",
+		},
+		{
+			name: "blank lines and trailing spaces are kept",
+			in:   "{\n\n  \"a\": 1  \n}",
+			want: "{\n\n  \"a\": 1  \n}",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(RemoveComments([]byte(tt.in))); got != tt.want {
+				t.Errorf("RemoveComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
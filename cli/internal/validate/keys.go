@@ -0,0 +1,100 @@
+package validate
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// knownTopLevelKeys are the opencode.json keys fifi or OpenCode understand
+var knownTopLevelKeys = map[string]bool{
+	"$schema":            true,
+	"agent":              true,
+	"autoshare":          true,
+	"autoupdate":         true,
+	"command":            true,
+	"disabled_providers": true,
+	"enabled_providers":  true,
+	"experimental":       true,
+	"formatter":          true,
+	"instructions":       true,
+	"keybinds":           true,
+	"layout":             true,
+	"lsp":                true,
+	"mcp":                true,
+	"mcpServers":         true,
+	"mode":               true,
+	"model":              true,
+	"permission":         true,
+	"plugin":             true,
+	"provider":           true,
+	"share":              true,
+	"small_model":        true,
+	"snapshot":           true,
+	"theme":              true,
+	"tools":              true,
+	"username":           true,
+	"watcher":            true,
+}
+
+// checkUnknownKeys warns about top-level keys that neither fifi nor
+// OpenCode know, since json.Unmarshal silently drops them
+func checkUnknownKeys(issues *issueList, content []byte) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(content, &keys); err != nil {
+		return
+	}
+
+	unknown := make([]string, 0)
+	for key := range keys {
+		if !knownTopLevelKeys[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+
+	for _, key := range unknown {
+		if suggestion := closestKey(key); suggestion != "" {
+			issues.warnf(CategoryUnknownKey, "unknown key %q in opencode.json (did you mean %q?)", key, suggestion)
+		} else {
+			issues.warnf(CategoryUnknownKey, "unknown key %q in opencode.json", key)
+		}
+	}
+}
+
+// closestKey returns the known key within two edits of key, or ""
+func closestKey(key string) string {
+	best, bestDistance := "", 3
+	for known := range knownTopLevelKeys {
+		if d := editDistance(key, known); d < bestDistance || (d == bestDistance && known < best) {
+			best, bestDistance = known, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the number of insertions, deletions, substitutions
+// and adjacent transpositions needed to turn a into b
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
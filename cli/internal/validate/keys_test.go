@@ -0,0 +1,64 @@
+package validate
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCheckUnknownKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{
+			name:    "known keys",
+			content: `{"$schema": "https://opencode.ai/config.json", "agent": {}, "mcp": {}, "tools": {}}`,
+		},
+		{
+			name:    "unknown key",
+			content: `{"agent": {}, "banana": true}`,
+			want:    []string{`unknown key "banana" in opencode.json`},
+		},
+		{
+			name:    "typo of a known key",
+			content: `{"agnet": {}}`,
+			want:    []string{`unknown key "agnet" in opencode.json (did you mean "agent"?)`},
+		},
+		{
+			name:    "several unknown keys are sorted",
+			content: `{"zebra": 1, "tool": {}, "alpha": 2}`,
+			want: []string{
+				`unknown key "alpha" in opencode.json`,
+				`unknown key "tool" in opencode.json (did you mean "tools"?)`,
+				`unknown key "zebra" in opencode.json`,
+			},
+		},
+		{
+			name:    "nested keys aren't checked",
+			content: `{"agent": {"banana": {}}}`,
+		},
+		{
+			name:    "invalid JSON",
+			content: `{"banana": `,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issues issueList
+			checkUnknownKeys(&issues, []byte(tt.content))
+
+			var got []string
+			for _, issue := range issues.issues {
+				if issue.Severity != SeverityWarning || issue.Category != CategoryUnknownKey {
+					t.Errorf("issue = %s %s, want %s %s", issue.Severity, issue.Category, SeverityWarning, CategoryUnknownKey)
+				}
+				got = append(got, issue.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("messages = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
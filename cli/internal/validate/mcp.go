@@ -0,0 +1,174 @@
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// checkMCPServers reports MCP servers that don't specify exactly one
+// transport (a command for stdio or a URL for remote servers) and remote
+// servers whose URL isn't a valid http(s) URL. Disabled servers in the mcp
+// section are skipped.
+func checkMCPServers(issues *issueList, config OpencodeConfig) {
+	for _, name := range sortedKeys(config.MCP) {
+		server := config.MCP[name]
+		if !server.IsEnabled() {
+			continue
+		}
+		if err := server.Check(name); err != nil {
+			issues.errorf(CategoryMCPServer, "%v", err)
+		}
+	}
+	for _, name := range sortedKeys(config.MCPServers) {
+		if err := config.MCPServers[name].Check(name); err != nil {
+			issues.errorf(CategoryMCPServer, "%v", err)
+		}
+	}
+}
+
+// Check reports whether the server, called name, is usable: it must have
+// exactly one transport, a command or an http(s) url
+func (s MCPServer) Check(name string) error {
+	switch {
+	case s.Command == "" && s.URL == "":
+		return fmt.Errorf("MCP server %s must specify either a command or a url", name)
+	case s.Command != "" && s.URL != "":
+		return fmt.Errorf("MCP server %s specifies both a command and a url; use only one", name)
+	case s.URL != "":
+		return checkMCPURL(name, s.URL)
+	}
+	return nil
+}
+
+// Check reports whether the server, called name, is usable: a local server
+// needs a command and a remote server an http(s) url, and it must not have
+// both or neither
+func (m MCP) Check(name string) error {
+	hasCommand := len(m.Command) > 0 && m.Command[0] != ""
+	switch {
+	case !hasCommand && m.URL == "":
+		return fmt.Errorf("MCP server %s must specify either a command or a url", name)
+	case hasCommand && m.URL != "":
+		return fmt.Errorf("MCP server %s specifies both a command and a url; use only one", name)
+	}
+
+	switch m.Type {
+	case "local":
+		if !hasCommand {
+			return fmt.Errorf("local MCP server %s needs a command", name)
+		}
+	case "remote":
+		if m.URL == "" {
+			return fmt.Errorf("remote MCP server %s needs a url", name)
+		}
+		return checkMCPURL(name, m.URL)
+	default:
+		return fmt.Errorf("MCP server %s has type %q; must be local or remote", name, m.Type)
+	}
+	return nil
+}
+
+// checkMCPURL reports whether rawURL, the url of the server called name, is
+// a valid http(s) URL
+func checkMCPURL(name, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("MCP server %s has an invalid url %q (must be http or https)", name, rawURL)
+	}
+	return nil
+}
+
+// envRefPattern matches ${NAME} and ${NAME:-default} shell-style references
+// and OpenCode's {env:NAME} substitutions
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(:?-[^}]*)?\}|\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// checkMCPEnvRefs warns about environment variables referenced in MCP
+// server commands, arguments, URLs, headers and environment values that are
+// neither set in fifi's environment nor defined by the server itself.
+// References with a default value and disabled servers are skipped.
+func checkMCPEnvRefs(issues *issueList, config OpencodeConfig) {
+	for _, name := range sortedKeys(config.MCPServers) {
+		server := config.MCPServers[name]
+		values := append([]string{server.Command, server.URL}, server.Args...)
+		reportMissingEnv(issues, name, values, server.Env)
+	}
+
+	for _, name := range sortedKeys(config.MCP) {
+		server := config.MCP[name]
+		if !server.IsEnabled() {
+			continue
+		}
+		values := append([]string{server.URL}, server.Command...)
+		for _, header := range server.Headers {
+			values = append(values, header)
+		}
+		reportMissingEnv(issues, name, values, server.Environment)
+	}
+}
+
+// reportMissingEnv warns once per variable referenced in values or in the
+// values of env that is neither a key of env nor set in the environment
+func reportMissingEnv(issues *issueList, server string, values []string, env map[string]string) {
+	for _, value := range env {
+		values = append(values, value)
+	}
+
+	missing := make(map[string]bool)
+	for _, value := range values {
+		_, unresolved := ExpandEnv(value, env)
+		for _, name := range unresolved {
+			missing[name] = true
+		}
+	}
+
+	for _, name := range sortedKeys(missing) {
+		issues.warnf(CategoryMCPEnv, "MCP server %s references environment variable %s, which is not set", server, name)
+	}
+}
+
+// ExpandEnv substitutes the ${NAME}, ${NAME:-default}, ${NAME-default} and
+// {env:NAME} references in value, looking each variable up in env first and
+// then in the environment. References that can't be resolved are left as
+// they are and their names returned in unresolved, in order of appearance.
+func ExpandEnv(value string, env map[string]string) (expanded string, unresolved []string) {
+	expanded = envRefPattern.ReplaceAllStringFunc(value, func(ref string) string {
+		match := envRefPattern.FindStringSubmatch(ref)
+		name, modifier := match[1], match[2]
+		if name == "" {
+			name = match[3]
+		}
+
+		resolved, ok := env[name]
+		if !ok {
+			resolved, ok = os.LookupEnv(name)
+		}
+		switch {
+		case strings.HasPrefix(modifier, ":-") && resolved == "":
+			return modifier[2:]
+		case strings.HasPrefix(modifier, "-") && !ok:
+			return modifier[1:]
+		case !ok:
+			if !slices.Contains(unresolved, name) {
+				unresolved = append(unresolved, name)
+			}
+			return ref
+		}
+		return resolved
+	})
+	return expanded, unresolved
+}
+
+// sortedKeys returns the keys of m in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
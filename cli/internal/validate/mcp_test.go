@@ -0,0 +1,135 @@
+package validate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheckMCPServers(t *testing.T) {
+	disabled := false
+
+	tests := []struct {
+		name    string
+		config  OpencodeConfig
+		wantErr string
+	}{
+		{
+			name: "local server",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"fs": {Type: "local", Command: []string{"npx", "-y", "server-filesystem"}},
+			}},
+		},
+		{
+			name: "remote server",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"docs": {Type: "remote", URL: "https://mcp.example.com/sse"},
+			}},
+		},
+		{
+			name: "remote server with ftp url",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"bad": {Type: "remote", URL: "ftp://x"},
+			}},
+			wantErr: `MCP server bad has an invalid url "ftp://x"`,
+		},
+		{
+			name: "local server without command",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"bad": {Type: "local"},
+			}},
+			wantErr: "MCP server bad must specify either a command or a url",
+		},
+		{
+			name: "local server with empty command",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"bad": {Type: "local", Command: []string{""}},
+			}},
+			wantErr: "MCP server bad must specify either a command or a url",
+		},
+		{
+			name: "local server with url only",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"bad": {Type: "local", URL: "https://mcp.example.com"},
+			}},
+			wantErr: "local MCP server bad needs a command",
+		},
+		{
+			name: "remote server with command only",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"bad": {Type: "remote", Command: []string{"server"}},
+			}},
+			wantErr: "remote MCP server bad needs a url",
+		},
+		{
+			name: "command and url",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"bad": {Type: "local", Command: []string{"server"}, URL: "https://mcp.example.com"},
+			}},
+			wantErr: "MCP server bad specifies both a command and a url",
+		},
+		{
+			name: "unknown type",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"bad": {Type: "stdio", Command: []string{"server"}},
+			}},
+			wantErr: `MCP server bad has type "stdio"`,
+		},
+		{
+			name: "disabled server is skipped",
+			config: OpencodeConfig{MCP: map[string]MCP{
+				"off": {Type: "local", Enabled: &disabled},
+			}},
+		},
+		{
+			name: "mcpServers command",
+			config: OpencodeConfig{MCPServers: map[string]MCPServer{
+				"fs": {Command: "npx", Args: []string{"-y", "server-filesystem"}},
+			}},
+		},
+		{
+			name: "mcpServers without transport",
+			config: OpencodeConfig{MCPServers: map[string]MCPServer{
+				"bad": {Args: []string{"-y"}},
+			}},
+			wantErr: "MCP server bad must specify either a command or a url",
+		},
+		{
+			name: "mcpServers command and url",
+			config: OpencodeConfig{MCPServers: map[string]MCPServer{
+				"bad": {Command: "server", URL: "https://mcp.example.com"},
+			}},
+			wantErr: "MCP server bad specifies both a command and a url",
+		},
+		{
+			name: "mcpServers invalid url",
+			config: OpencodeConfig{MCPServers: map[string]MCPServer{
+				"bad": {URL: "mcp.example.com"},
+			}},
+			wantErr: `MCP server bad has an invalid url "mcp.example.com"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var issues issueList
+			checkMCPServers(&issues, tt.config)
+
+			if tt.wantErr == "" {
+				if len(issues.issues) > 0 {
+					t.Fatalf("unexpected issues: %v", issues.issues)
+				}
+				return
+			}
+			if len(issues.issues) != 1 {
+				t.Fatalf("got %d issues %v, want 1", len(issues.issues), issues.issues)
+			}
+			issue := issues.issues[0]
+			if issue.Severity != SeverityError || issue.Category != CategoryMCPServer {
+				t.Errorf("issue = %s %s, want %s %s", issue.Severity, issue.Category, SeverityError, CategoryMCPServer)
+			}
+			if !strings.Contains(issue.Message, tt.wantErr) {
+				t.Errorf("message = %q, want it to contain %q", issue.Message, tt.wantErr)
+			}
+		})
+	}
+}
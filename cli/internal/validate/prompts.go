@@ -0,0 +1,99 @@
+package validate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// checkPrompt reports an agent's prompt file if it is outside the project,
+// doesn't exist, looks binary or contains nothing but whitespace
+func checkPrompt(issues *issueList, targetDir, name string, agent Agent) {
+	if agent.Prompt == "" {
+		return
+	}
+	if !promptInProject(agent.Prompt) {
+		// Never read files outside the project, e.g. ../../etc/passwd
+		issues.agentIssue(SeverityError, CategoryPromptPath, name, "prompt",
+			"prompt file for agent %s is outside the project directory: %s", name, agent.Prompt)
+		return
+	}
+
+	content, err := os.ReadFile(filepath.Join(targetDir, agent.Prompt))
+	if os.IsNotExist(err) {
+		issues.agentIssue(SeverityError, CategoryMissingPrompt, name, "prompt",
+			"prompt file for agent %s not found: %s", name, agent.Prompt)
+		return
+	}
+	if err != nil {
+		return
+	}
+	if bytes.IndexByte(content, 0) >= 0 || !utf8.Valid(content) {
+		// e.g. prompt pointing at an image; the model would get garbage
+		issues.agentIssue(SeverityWarning, CategoryBinaryPrompt, name, "prompt",
+			"prompt file for agent %s is not UTF-8 text (binary file?): %s", name, agent.Prompt)
+		return
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		issues.agentIssue(SeverityWarning, CategoryEmptyPrompt, name, "prompt",
+			"prompt file for agent %s is empty: %s", name, agent.Prompt)
+	}
+}
+
+// promptInProject reports whether a prompt path is relative and stays
+// inside the project directory once cleaned
+func promptInProject(prompt string) bool {
+	return filepath.IsLocal(filepath.FromSlash(prompt))
+}
+
+// checkSharedPrompts warns when several agents reference the same prompt
+// file. Sharing can be intentional but is usually a copy-paste mistake.
+func checkSharedPrompts(issues *issueList, config OpencodeConfig) {
+	agentsByPrompt := make(map[string][]string)
+	var prompts []string
+	for _, name := range agentNames(config) {
+		prompt := config.Agent[name].Prompt
+		if prompt == "" {
+			continue
+		}
+		prompt = filepath.Clean(prompt)
+		if agentsByPrompt[prompt] == nil {
+			prompts = append(prompts, prompt)
+		}
+		agentsByPrompt[prompt] = append(agentsByPrompt[prompt], name)
+	}
+
+	for _, prompt := range prompts {
+		if agents := agentsByPrompt[prompt]; len(agents) > 1 {
+			issues.warnf(CategorySharedPrompt, "agents %s all use the same prompt file %s", strings.Join(agents, ", "), filepath.ToSlash(prompt))
+		}
+	}
+}
+
+// checkOrphanedPrompts warns about files in the prompts directory that no
+// agent's prompt field references
+func checkOrphanedPrompts(issues *issueList, targetDir string, config OpencodeConfig) {
+	promptsDir := filepath.Join(targetDir, ".opencode", "prompts")
+	entries, err := os.ReadDir(promptsDir)
+	if err != nil {
+		return
+	}
+
+	referenced := make(map[string]bool)
+	for _, agent := range config.Agent {
+		if agent.Prompt != "" {
+			referenced[filepath.Join(targetDir, agent.Prompt)] = true
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if !referenced[filepath.Join(promptsDir, entry.Name())] {
+			issues.warnf(CategoryOrphanedPrompt, "prompt file .opencode/prompts/%s is not referenced by any agent", entry.Name())
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package validate
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+//go:embed opencode.schema.json
+var opencodeSchemaJSON []byte
+
+const opencodeSchemaURL = "opencode.schema.json"
+
+var (
+	schemaOnce     sync.Once
+	opencodeSchema *jsonschema.Schema
+	schemaErr      error
+)
+
+// compiledSchema compiles the embedded opencode.json schema on first use
+func compiledSchema() (*jsonschema.Schema, error) {
+	schemaOnce.Do(func() {
+		compiler := jsonschema.NewCompiler()
+		if err := compiler.AddResource(opencodeSchemaURL, bytes.NewReader(opencodeSchemaJSON)); err != nil {
+			schemaErr = err
+			return
+		}
+		opencodeSchema, schemaErr = compiler.Compile(opencodeSchemaURL)
+	})
+	return opencodeSchema, schemaErr
+}
+
+// checkSchema validates the decoded opencode.json against the embedded
+// schema and reports every violation with its JSON path
+func checkSchema(issues *issueList, doc interface{}) error {
+	schema, err := compiledSchema()
+	if err != nil {
+		return fmt.Errorf("failed to load opencode.json schema: %w", err)
+	}
+	return reportSchemaViolations(issues, schema, doc, "")
+}
+
+// checkReferencedSchema validates the decoded opencode.json against the
+// schema its $schema field points to, a URL or a path relative to
+// targetDir. A schema that can't be loaded only produces a warning, leaving
+// the built-in checks to stand on their own.
+func checkReferencedSchema(issues *issueList, targetDir, ref string, doc interface{}) {
+	schema, err := loadReferencedSchema(targetDir, ref)
+	if err != nil {
+		issues.warnf(CategorySchema, "couldn't load $schema %s, using built-in checks only: %v", ref, err)
+		return
+	}
+	if err := reportSchemaViolations(issues, schema, doc, " ("+ref+")"); err != nil {
+		issues.warnf(CategorySchema, "couldn't validate against $schema %s: %v", ref, err)
+	}
+}
+
+// loadReferencedSchema fetches or reads the schema at ref and compiles it
+func loadReferencedSchema(targetDir, ref string) (*jsonschema.Schema, error) {
+	var content []byte
+	if u, err := url.Parse(ref); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		content, err = fetchSchema(ref)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		path := ref
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(targetDir, filepath.FromSlash(path))
+		}
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(ref, bytes.NewReader(content)); err != nil {
+		return nil, err
+	}
+	return compiler.Compile(ref)
+}
+
+// schemaClient fetches remote schemas; validation shouldn't hang on a slow
+// network
+var schemaClient = &http.Client{Timeout: 10 * time.Second}
+
+func fetchSchema(ref string) ([]byte, error) {
+	resp, err := schemaClient.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// reportSchemaViolations validates doc against schema and reports every
+// violation with its JSON path, appending origin to each message
+func reportSchemaViolations(issues *issueList, schema *jsonschema.Schema, doc interface{}, origin string) error {
+	err := schema.Validate(doc)
+	if err == nil {
+		return nil
+	}
+	validationErr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return fmt.Errorf("failed to validate opencode.json against schema: %w", err)
+	}
+
+	violations := schemaViolations(validationErr)
+	sort.SliceStable(violations, func(i, j int) bool {
+		if violations[i].InstanceLocation != violations[j].InstanceLocation {
+			return violations[i].InstanceLocation < violations[j].InstanceLocation
+		}
+		return violations[i].Message < violations[j].Message
+	})
+	for _, leaf := range violations {
+		location := leaf.InstanceLocation
+		if location == "" {
+			location = "/"
+		}
+		issues.agentIssue(SeverityError, CategorySchema, schemaAgent(location), location,
+			"opencode.json %s: %s%s", location, leaf.Message, origin)
+	}
+	return nil
+}
+
+// schemaViolations returns the most specific causes of a validation error,
+// skipping the wrappers that only say a subschema didn't match
+func schemaViolations(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	for _, cause := range err.Causes {
+		leaves = append(leaves, schemaViolations(cause)...)
+	}
+	return leaves
+}
+
+// schemaAgent returns the agent a JSON pointer such as /agent/docs/temperature
+// points into, or "" if it isn't inside an agent
+func schemaAgent(location string) string {
+	parts := strings.Split(strings.TrimPrefix(location, "/"), "/")
+	if len(parts) < 2 || parts[0] != "agent" {
+		return ""
+	}
+	// Unescape the JSON pointer encoding of "~" and "/"
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(parts[1])
+}
@@ -0,0 +1,191 @@
+package validate
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// scriptExtensions are the tool file extensions OpenCode loads as
+// JavaScript or TypeScript modules
+var scriptExtensions = map[string]bool{
+	".js":  true,
+	".mjs": true,
+	".cjs": true,
+	".jsx": true,
+	".ts":  true,
+	".mts": true,
+	".cts": true,
+	".tsx": true,
+}
+
+// exportPattern matches an ES module or CommonJS export
+var exportPattern = regexp.MustCompile(`(?m)^\s*export\b|\bmodule\.exports\b|\bexports\.`)
+
+// checkToolFiles warns about JavaScript and TypeScript tool files that are
+// empty, export nothing, or have unbalanced brackets, strings or comments.
+// This is a sanity pass for truncated or corrupted files, not a parser.
+func checkToolFiles(issues *issueList, toolDir string) {
+	filepath.WalkDir(toolDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !scriptExtensions[filepath.Ext(d.Name())] {
+			return nil
+		}
+		rel, err := filepath.Rel(toolDir, path)
+		if err != nil {
+			return nil
+		}
+		name := ".opencode/tool/" + filepath.ToSlash(rel)
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			issues.warnf(CategoryToolSyntax, "tool file %s can't be read: %v", name, err)
+			return nil
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			issues.warnf(CategoryToolSyntax, "tool file %s is empty", name)
+			return nil
+		}
+		if err := checkBalanced(content); err != nil {
+			issues.warnf(CategoryToolSyntax, "tool file %s looks truncated or corrupted: %v", name, err)
+			return nil
+		}
+		if !exportPattern.Match(content) {
+			issues.warnf(CategoryToolSyntax, "tool file %s doesn't export anything", name)
+		}
+		return nil
+	})
+}
+
+// checkBalanced verifies that brackets in JavaScript source are balanced and
+// that strings, template literals, regular expressions and comments are
+// terminated
+func checkBalanced(src []byte) error {
+	type open struct {
+		char byte
+		line int
+	}
+	var (
+		stack []open
+		line  = 1
+		// prev is the last significant character, used to tell a regular
+		// expression from a division
+		prev byte
+	)
+	closing := map[byte]byte{')': '(', ']': '[', '}': '{'}
+
+	// skipUntil advances i past the terminator of a string or regular
+	// expression, honoring backslash escapes
+	skipUntil := func(i int, quote byte, what string) (int, error) {
+		start := line
+		inClass := false
+		for i++; i < len(src); i++ {
+			c := src[i]
+			switch {
+			case c == '\\':
+				i++
+				if i < len(src) && src[i] == '\n' {
+					line++
+				}
+			case c == '\n':
+				if quote != '`' {
+					return 0, fmt.Errorf("unterminated %s on line %d", what, start)
+				}
+				line++
+			case quote == '/' && c == '[':
+				inClass = true
+			case quote == '/' && c == ']':
+				inClass = false
+			case c == quote && !inClass:
+				return i, nil
+			case quote == '`' && c == '$' && i+1 < len(src) && src[i+1] == '{':
+				// Template substitution: scan it as code until its closing brace
+				return i, nil
+			}
+		}
+		return 0, fmt.Errorf("unterminated %s starting on line %d", what, start)
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '/':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			line++
+			continue
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := strings.Index(string(src[i+2:]), "*/")
+			if end < 0 {
+				return fmt.Errorf("unterminated comment starting on line %d", line)
+			}
+			line += strings.Count(string(src[i:i+2+end]), "\n")
+			i += end + 3
+			continue
+		case c == '"' || c == '\'':
+			end, err := skipUntil(i, c, "string")
+			if err != nil {
+				return err
+			}
+			i = end
+		case c == '`':
+			end, err := skipUntil(i, c, "template literal")
+			if err != nil {
+				return err
+			}
+			i = end
+			if src[i] == '$' {
+				stack = append(stack, open{'`', line})
+				i++
+			}
+		case c == '/' && strings.IndexByte("(,=:[!&|?{};+-*%<>~^", prev) >= 0:
+			end, err := skipUntil(i, c, "regular expression")
+			if err != nil {
+				return err
+			}
+			i = end
+		case c == '(' || c == '[' || c == '{':
+			stack = append(stack, open{c, line})
+		case c == ')' || c == ']' || c == '}':
+			if len(stack) == 0 {
+				return fmt.Errorf("unexpected %q on line %d", c, line)
+			}
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if top.char == '`' && c == '}' {
+				// End of a template substitution: resume the template literal
+				end, err := skipUntil(i, '`', "template literal")
+				if err != nil {
+					return err
+				}
+				i = end
+				if src[i] == '$' {
+					stack = append(stack, open{'`', line})
+					i++
+				}
+				break
+			}
+			if top.char != closing[c] {
+				return fmt.Errorf("%q on line %d doesn't match %q on line %d", c, line, top.char, top.line)
+			}
+		}
+		prev = src[i]
+	}
+
+	if len(stack) > 0 {
+		top := stack[len(stack)-1]
+		if top.char == '`' {
+			return fmt.Errorf("unterminated template literal on line %d", top.line)
+		}
+		return fmt.Errorf("unclosed %q from line %d", top.char, top.line)
+	}
+	return nil
+}
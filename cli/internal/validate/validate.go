@@ -2,21 +2,28 @@ package validate
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 // OpencodeConfig represents the structure of opencode.json
 type OpencodeConfig struct {
+	Schema     string               `json:"$schema,omitempty"`
 	Agent      map[string]Agent     `json:"agent"`
 	Tools      map[string]bool      `json:"tools"`
 	MCPServers map[string]MCPServer `json:"mcpServers"`
+	MCP        map[string]MCP       `json:"mcp"`
 }
 
 type Agent struct {
 	Description string                 `json:"description"`
 	Type        string                 `json:"type"`
+	Mode        string                 `json:"mode,omitempty"`
 	Temperature float64                `json:"temperature"`
 	Prompt      string                 `json:"prompt,omitempty"`
 	Tools       interface{}            `json:"tools,omitempty"` // Can be []string or map[string]interface{}
@@ -30,106 +37,308 @@ type MCPServer struct {
 	Env     map[string]string `json:"env,omitempty"`
 }
 
-// Validate checks if opencode.json exists and is valid in the target directory
-func Validate(targetDir string) error {
+// MCP is an entry of OpenCode's mcp section: a local server started with
+// Command or a remote server reached at URL
+type MCP struct {
+	Type        string            `json:"type"`
+	Command     []string          `json:"command,omitempty"`
+	Environment map[string]string `json:"environment,omitempty"`
+	URL         string            `json:"url,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+	Enabled     *bool             `json:"enabled,omitempty"`
+}
+
+// IsEnabled reports whether OpenCode will start the server
+func (m MCP) IsEnabled() bool {
+	return m.Enabled == nil || *m.Enabled
+}
+
+// Options controls which checks Validate reports
+type Options struct {
+	// Ignore suppresses findings of the given categories
+	Ignore []Category
+	// SchemaOnly checks opencode.json against its JSON Schema and nothing else
+	SchemaOnly bool
+	// ReferencedSchema also validates against the schema named by the
+	// config's $schema field, fetching it if it's a URL
+	ReferencedSchema bool
+	// ConfigFile is the config file to validate instead of the opencode.json
+	// in the target directory. The .opencode directory and prompt paths are
+	// then resolved relative to the directory containing it.
+	ConfigFile string
+}
+
+func (o Options) ignored(c Category) bool {
+	for _, ignored := range o.Ignore {
+		if ignored == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks opencode.json and the .opencode directory in the target
+// directory and returns every issue found. An error is returned only when
+// the configuration can't be checked at all: it wraps ErrConfigNotFound if
+// there is no opencode.json, and is a *ParseError if it can't be parsed.
+func Validate(targetDir string, opts Options) ([]Issue, error) {
+	configPath := opts.ConfigFile
+	if configPath != "" {
+		targetDir = filepath.Dir(configPath)
+	}
+
 	// Resolve target directory
 	if targetDir == "" {
 		var err error
 		targetDir, err = os.Getwd()
 		if err != nil {
-			return fmt.Errorf("failed to get current directory: %w", err)
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
 		}
 	}
 
-	// Check if opencode.json exists
-	opencodeJSONPath := filepath.Join(targetDir, "opencode.json")
-	if _, err := os.Stat(opencodeJSONPath); os.IsNotExist(err) {
-		return fmt.Errorf("opencode.json not found in %s", targetDir)
+	// Check if opencode.json (or opencode.jsonc) exists
+	if configPath == "" {
+		configPath = ConfigPath(targetDir)
+		if _, err := os.Stat(configPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w in %s", ErrConfigNotFound, targetDir)
+		}
+	} else if _, err := os.Stat(configPath); os.IsNotExist(err) {
+		return nil, fmt.Errorf("%w: no such file %s", ErrConfigNotFound, configPath)
 	}
 
-	// Read and parse opencode.json
-	content, err := os.ReadFile(opencodeJSONPath)
+	// Read and parse opencode.json, ignoring comments
+	content, err := readConfig(configPath)
 	if err != nil {
-		return fmt.Errorf("failed to read opencode.json: %w", err)
+		return nil, fmt.Errorf("failed to read opencode.json: %w", err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, &ParseError{Err: err}
 	}
 
+	issues := &issueList{opts: opts}
+	if err := checkSchema(issues, doc); err != nil {
+		return nil, err
+	}
+	if opts.ReferencedSchema {
+		if object, ok := doc.(map[string]interface{}); ok {
+			if ref, ok := object["$schema"].(string); ok && ref != "" {
+				checkReferencedSchema(issues, targetDir, ref, doc)
+			}
+		}
+	}
+	if opts.SchemaOnly {
+		return issues.issues, nil
+	}
+	checkUnknownKeys(issues, content)
+
 	var config OpencodeConfig
 	if err := json.Unmarshal(content, &config); err != nil {
-		return fmt.Errorf("failed to parse opencode.json: %w", err)
+		// Type mismatches are already reported as schema violations
+		if HasErrors(issues.issues) {
+			return issues.issues, nil
+		}
+		return nil, &ParseError{Err: err}
 	}
 
 	// Validate structure
 	if len(config.Agent) == 0 {
-		return fmt.Errorf("no agent defined in opencode.json")
+		issues.errorf(CategoryNoAgents, "no agent defined in opencode.json")
 	}
 
-	// Check if .opencode directory exists
+	// Check that the .opencode directory exists and is a directory
 	opencodeDirPath := filepath.Join(targetDir, ".opencode")
-	if _, err := os.Stat(opencodeDirPath); os.IsNotExist(err) {
-		return fmt.Errorf(".opencode directory not found in %s", targetDir)
+	if checkDirectory(issues, opencodeDirPath, ".opencode", targetDir) {
+		// Check that the prompts and tool directories exist and aren't empty
+		for _, name := range []string{"prompts", "tool"} {
+			dirPath := filepath.Join(opencodeDirPath, name)
+			if checkDirectory(issues, dirPath, ".opencode/"+name, targetDir) && !hasFiles(dirPath) {
+				issues.errorf(CategoryEmptyDirectory, ".opencode/%s directory in %s contains no files", name, targetDir)
+			}
+		}
 	}
 
-	// Check if prompts directory exists
-	promptsDirPath := filepath.Join(opencodeDirPath, "prompts")
-	if _, err := os.Stat(promptsDirPath); os.IsNotExist(err) {
-		return fmt.Errorf(".opencode/prompts directory not found in %s", targetDir)
+	customTools := customToolNames(filepath.Join(opencodeDirPath, "tool"))
+	for _, agentName := range agentNames(config) {
+		agent := config.Agent[agentName]
+
+		checkPrompt(issues, targetDir, agentName, agent)
+		checkAgentType(issues, agentName, agent)
+		checkTemperature(issues, agentName, agent)
+		checkToolReferences(issues, agentName, agent, config, customTools)
 	}
 
-	// Check if tool directory exists
-	toolDirPath := filepath.Join(opencodeDirPath, "tool")
-	if _, err := os.Stat(toolDirPath); os.IsNotExist(err) {
-		return fmt.Errorf(".opencode/tool directory not found in %s", targetDir)
+	checkToolFiles(issues, filepath.Join(opencodeDirPath, "tool"))
+	checkSharedPrompts(issues, config)
+	checkOrphanedPrompts(issues, targetDir, config)
+	checkMCPServers(issues, config)
+	checkMCPEnvRefs(issues, config)
+
+	return issues.issues, nil
+}
+
+// checkDirectory reports a missing-directory issue when path, shown as name,
+// doesn't exist or isn't a directory, and returns whether it is one
+func checkDirectory(issues *issueList, path, name, targetDir string) bool {
+	info, err := os.Stat(path)
+	switch {
+	case os.IsNotExist(err):
+		issues.errorf(CategoryMissingDirectory, "%s directory not found in %s", name, targetDir)
+		return false
+	case err != nil:
+		issues.errorf(CategoryMissingDirectory, "cannot access %s in %s: %v", name, targetDir, err)
+		return false
+	case !info.IsDir():
+		issues.errorf(CategoryMissingDirectory, "%s exists in %s but is not a directory", name, targetDir)
+		return false
 	}
+	return true
+}
 
-	// Validate that prompt files referenced in agent exist
-	for agentName, agent := range config.Agent {
-		if agent.Prompt != "" {
-			promptPath := filepath.Join(targetDir, agent.Prompt)
-			if _, err := os.Stat(promptPath); os.IsNotExist(err) {
-				return fmt.Errorf("prompt file for agent %s not found: %s", agentName, agent.Prompt)
-			}
+// hasFiles reports whether dir or any of its subdirectories contains a
+// file other than dotfiles such as .gitkeep
+func hasFiles(dir string) bool {
+	found := false
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || found {
+			return nil
+		}
+		if !d.IsDir() && !strings.HasPrefix(d.Name(), ".") {
+			found = true
+			return filepath.SkipAll
 		}
+		return nil
+	})
+	return found
+}
+
+// agentNames returns the configured agent names in sorted order
+func agentNames(config OpencodeConfig) []string {
+	names := make([]string, 0, len(config.Agent))
+	for name := range config.Agent {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LoadConfig reads and parses the opencode.json in targetDir, which may
+// contain comments or be named opencode.jsonc. Like Validate, it wraps
+// ErrConfigNotFound or returns a *ParseError.
+func LoadConfig(targetDir string) (OpencodeConfig, error) {
+	config, err := LoadConfigFile(ConfigPath(targetDir))
+	if errors.Is(err, ErrConfigNotFound) {
+		return config, fmt.Errorf("%w in %s", ErrConfigNotFound, targetDir)
 	}
+	return config, err
+}
+
+// LoadConfigFile reads and parses the OpenCode config file at path. Like
+// LoadConfig, it wraps ErrConfigNotFound or returns a *ParseError.
+func LoadConfigFile(path string) (OpencodeConfig, error) {
+	var config OpencodeConfig
+	content, err := readConfig(path)
+	if os.IsNotExist(err) {
+		return config, fmt.Errorf("%w: no such file %s", ErrConfigNotFound, path)
+	}
+	if err != nil {
+		return config, fmt.Errorf("failed to read opencode.json: %w", err)
+	}
+	if err := json.Unmarshal(content, &config); err != nil {
+		return config, &ParseError{Err: err}
+	}
+	return config, nil
+}
+
+// Summary describes what an opencode.json configures
+type Summary struct {
+	Agents        int      `json:"agents"`
+	MCPServers    int      `json:"mcpServers"`
+	EnabledTools  int      `json:"enabledTools"`
+	DisabledTools int      `json:"disabledTools"`
+	AgentNames    []string `json:"agentNames"`
+	// AgentDetails describes each agent, sorted by name
+	AgentDetails []AgentSummary `json:"agentDetails"`
+}
+
+// AgentSummary describes a single agent
+type AgentSummary struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type,omitempty"`
+	Temperature float64 `json:"temperature"`
+	Prompt      string  `json:"prompt,omitempty"`
+	// HasPrompt reports whether the prompt file exists
+	HasPrompt bool `json:"hasPrompt"`
+}
 
-	return nil
+// agentType returns the agent's type, falling back to OpenCode's mode field
+func agentType(agent Agent) string {
+	if agent.Type != "" {
+		return agent.Type
+	}
+	return agent.Mode
 }
 
-// GetSummary returns a summary of the opencode.json configuration
-func GetSummary(targetDir string) (string, error) {
+// Summarize reads the opencode.json in targetDir and summarizes it
+func Summarize(targetDir string) (*Summary, error) {
 	if targetDir == "" {
 		var err error
 		targetDir, err = os.Getwd()
 		if err != nil {
-			return "", fmt.Errorf("failed to get current directory: %w", err)
+			return nil, fmt.Errorf("failed to get current directory: %w", err)
 		}
 	}
 
-	opencodeJSONPath := filepath.Join(targetDir, "opencode.json")
-	content, err := os.ReadFile(opencodeJSONPath)
+	config, err := LoadConfig(targetDir)
 	if err != nil {
-		return "", fmt.Errorf("failed to read opencode.json: %w", err)
+		return nil, err
 	}
+	return summarize(targetDir, config), nil
+}
 
-	var config OpencodeConfig
-	if err := json.Unmarshal(content, &config); err != nil {
-		return "", fmt.Errorf("failed to parse opencode.json: %w", err)
+// SummarizeFile summarizes the config file at path, resolving prompt paths
+// relative to the directory containing it
+func SummarizeFile(path string) (*Summary, error) {
+	config, err := LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return summarize(filepath.Dir(path), config), nil
+}
+
+// summarize describes config, checking prompt files in targetDir
+func summarize(targetDir string, config OpencodeConfig) *Summary {
+	summary := &Summary{
+		Agents:     len(config.Agent),
+		MCPServers: len(config.MCP) + len(config.MCPServers),
+		AgentNames: agentNames(config),
 	}
 
-	summary := fmt.Sprintf("Configuration Summary:\n")
-	summary += fmt.Sprintf("  Agent: %d\n", len(config.Agent))
-	summary += fmt.Sprintf("  MCP Servers: %d\n", len(config.MCPServers))
+	for _, name := range summary.AgentNames {
+		agent := config.Agent[name]
+		detail := AgentSummary{
+			Name:        name,
+			Type:        agentType(agent),
+			Temperature: agent.Temperature,
+			Prompt:      agent.Prompt,
+		}
+		if agent.Prompt != "" && promptInProject(agent.Prompt) {
+			if info, err := os.Stat(filepath.Join(targetDir, agent.Prompt)); err == nil && !info.IsDir() {
+				detail.HasPrompt = true
+			}
+		}
+		summary.AgentDetails = append(summary.AgentDetails, detail)
+	}
 
 	// Count enabled and disabled tools
-	enabledTools := 0
-	disabledTools := 0
 	for _, enabled := range config.Tools {
 		if enabled {
-			enabledTools++
+			summary.EnabledTools++
 		} else {
-			disabledTools++
+			summary.DisabledTools++
 		}
 	}
-	summary += fmt.Sprintf("  Tools (enabled/disabled): %d/%d\n", enabledTools, disabledTools)
 
-	return summary, nil
+	return summary
 }